@@ -5,11 +5,29 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/baditaflorin/go_services_dashboard/internal/alerting"
 	"github.com/baditaflorin/go_services_dashboard/internal/api"
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
 	"github.com/baditaflorin/go_services_dashboard/internal/config"
+	"github.com/baditaflorin/go_services_dashboard/internal/discovery"
+	"github.com/baditaflorin/go_services_dashboard/internal/dnscheck"
+	"github.com/baditaflorin/go_services_dashboard/internal/docker"
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+	"github.com/baditaflorin/go_services_dashboard/internal/maintenance"
 	"github.com/baditaflorin/go_services_dashboard/internal/models"
 	"github.com/baditaflorin/go_services_dashboard/internal/monitor"
+	"github.com/baditaflorin/go_services_dashboard/internal/network"
+	"github.com/baditaflorin/go_services_dashboard/internal/pause"
+	"github.com/baditaflorin/go_services_dashboard/internal/statuspage"
+	"github.com/baditaflorin/go_services_dashboard/internal/tlscheck"
 )
 
 const version = "1.9.0"
@@ -20,31 +38,228 @@ func main() {
 		port = "43565"
 	}
 
+	if err := audit.SetTrustedProxies(config.GetEnvList("TRUSTED_PROXIES")); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// 1. Initialize Registry
 	registry := models.NewRegistry()
 
 	// 2. Load Services
 	config.LoadServices(registry)
 
-	// 3. Start Monitor (Hybrid: Internal -> Public)
-	mon := monitor.NewMonitor(registry)
+	// 3. Open check history store
+	dataDir := config.GetEnv("DATA_DIR", "data")
+	historyOpts := history.DefaultOptions()
+	historyOpts.Retention = time.Duration(config.GetEnvInt("HISTORY_RETENTION_DAYS", 30)) * 24 * time.Hour
+	historyOpts.RawWindow = config.GetEnvDuration("HISTORY_RAW_WINDOW", historyOpts.RawWindow)
+	historyOpts.DownsampleStep = config.GetEnvDuration("HISTORY_DOWNSAMPLE_STEP", historyOpts.DownsampleStep)
+	store, err := history.NewStore(filepath.Join(dataDir, "history"), historyOpts)
+	if err != nil {
+		log.Printf("History disabled: %v", err)
+		store = nil
+	} else {
+		go store.Maintain(time.Hour)
+	}
+
+	// 4. Start Monitor (Hybrid: Internal -> Public)
+	mon := monitor.NewMonitor(registry, store)
+	mon.SetDefaultSLO(config.GetEnvFloat("DEFAULT_SLO", monitor.DefaultSLO))
+	mon.SetWorkers(config.GetEnvInt("CHECK_WORKERS", monitor.DefaultWorkers))
+	mon.SetInterval(config.GetEnvDuration("CHECK_INTERVAL", monitor.DefaultInterval))
+	mon.SetJitter(config.GetEnvFloat("CHECK_JITTER", monitor.DefaultJitter))
+	mon.SetTimeout(config.GetEnvDuration("CHECK_TIMEOUT", monitor.DefaultTimeout))
+	breakerOpts := monitor.DefaultBreakerOptions()
+	mon.SetBreaker(monitor.BreakerOptions{
+		Threshold:   config.GetEnvInt("BREAKER_THRESHOLD", breakerOpts.Threshold),
+		CoolDown:    config.GetEnvDuration("BREAKER_COOLDOWN", breakerOpts.CoolDown),
+		MaxCoolDown: config.GetEnvDuration("BREAKER_MAX_COOLDOWN", breakerOpts.MaxCoolDown),
+	})
+
+	correlationOpts := monitor.DefaultCorrelationOptions()
+	mon.SetCorrelation(monitor.CorrelationOptions{
+		Window:      config.GetEnvDuration("CORRELATION_WINDOW", correlationOpts.Window),
+		MinServices: config.GetEnvInt("CORRELATION_MIN_SERVICES", correlationOpts.MinServices),
+	})
+
+	// Maintenance windows and silences
+	windows, err := maintenance.NewStore(filepath.Join(dataDir, "maintenance.json"))
+	if err != nil {
+		log.Printf("Maintenance windows disabled: %v", err)
+		windows = nil
+	}
+	mon.SetMaintenance(windows)
+
+	// Paused services and categories
+	pauses, err := pause.NewStore(filepath.Join(dataDir, "pauses.json"))
+	if err != nil {
+		log.Printf("Pausing disabled: %v", err)
+		pauses = nil
+	}
+	mon.SetPauses(pauses)
+
+	// Incidents derived from status transitions
+	incidentOpts := incident.DefaultOptions()
+	incidentOpts.Retention = time.Duration(config.GetEnvInt("INCIDENT_RETENTION_DAYS", 90)) * 24 * time.Hour
+	incidentOpts.MaxChecks = config.GetEnvInt("INCIDENT_MAX_CHECKS", incidentOpts.MaxChecks)
+	incidents, err := incident.NewTracker(filepath.Join(dataDir, "incidents.json"), incidentOpts)
+	if err != nil {
+		log.Printf("Incidents disabled: %v", err)
+		incidents = nil
+	} else {
+		go incidents.Run(time.Minute)
+	}
+	mon.SetIncidents(incidents)
+
+	// Alerting on status transitions (config/alerts.json)
+	var alerts *alerting.Manager
+	if alertCfg, ok, err := config.LoadAlerting(); err != nil {
+		log.Printf("Alerting disabled: %v", err)
+	} else if ok {
+		alerts, err = alerting.NewManager(alertCfg)
+		if err != nil {
+			log.Printf("Alerting disabled: %v", err)
+		} else {
+			mon.OnTransition(func(t monitor.Transition) {
+				if !t.Silenced && !t.Impacted {
+					alerts.Observe(t.Service, t.From, t.To, t.Error, t.CircuitTripped, t.At)
+				}
+			})
+			log.Printf("Alerting enabled with %d notifiers", len(alertCfg.Notifiers))
+		}
+	}
 	go mon.Start()
+	go mon.RunCompliance(config.GetEnvDuration("COMPLIANCE_INTERVAL", monitor.DefaultComplianceInterval))
 
-	// 4. Initialize Handlers
+	// Hot-reload of config/services.json (file watch, SIGHUP, POST /api/admin/reload)
+	reloader := config.NewReloader(registry)
+	go reloader.Watch(config.GetEnvDuration("CONFIG_WATCH_INTERVAL", 5*time.Second))
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if _, err := reloader.Reload(); err != nil {
+				log.Printf("Config reload failed: %v", err)
+			}
+		}
+	}()
+
+	// Docker Engine API discovery (opt-in, needs the docker socket mounted)
+	dockerClient := docker.NewClient(config.GetEnv("DOCKER_HOST", docker.DefaultSocket))
+	if config.GetEnv("DISCOVERY_ENABLED", "false") == "true" {
+		opts := discovery.Options{
+			PublicURLFormat: config.GetEnv("SERVICE_BASE_URL", ""),
+			IncludeStopped:  config.GetEnv("DISCOVERY_INCLUDE_STOPPED", "false") == "true",
+		}
+		if labels := config.GetEnv("DISCOVERY_LABELS", ""); labels != "" {
+			opts.Labels = strings.Split(labels, ",")
+		}
+		if pattern := config.GetEnv("DISCOVERY_NAME_PATTERN", "^go_"); pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				log.Fatalf("Invalid DISCOVERY_NAME_PATTERN: %v", err)
+			}
+			opts.NamePattern = re
+		}
+		provider := discovery.NewProvider(dockerClient, registry, opts)
+		go provider.Run(config.GetEnvDuration("DISCOVERY_INTERVAL", time.Minute))
+		log.Printf("Docker discovery enabled")
+	}
+
+	// Audit log of administrative actions
+	auditLog, err := audit.NewLog(filepath.Join(dataDir, "audit.log"))
+	if err != nil {
+		log.Printf("Audit log disabled: %v", err)
+	}
+
+	// Docker network membership audit (opt-in, needs the docker socket mounted)
+	var auditor *network.Auditor
+	if config.GetEnv("NETWORK_AUDIT_ENABLED", "false") == "true" {
+		auditor = network.NewAuditor(dockerClient, registry,
+			config.GetEnv("DOCKER_NETWORK", "pentest_network"),
+			config.GetEnv("NETWORK_REMEDIATION", "false") == "true",
+			config.GetEnv("NETWORK_AUTO_REMEDIATE", "false") == "true",
+			auditLog)
+		go auditor.Run(config.GetEnvDuration("NETWORK_AUDIT_INTERVAL", 5*time.Minute))
+		log.Printf("Network audit enabled")
+	}
+
+	// TLS certificate expiry of the public hosts
+	if config.GetEnv("TLS_CHECK_ENABLED", "true") == "true" {
+		tlsOpts := tlscheck.DefaultOptions()
+		tlsOpts.WarnDays = config.GetEnvInt("TLS_WARN_DAYS", tlsOpts.WarnDays)
+		tlsOpts.CriticalDays = config.GetEnvInt("TLS_CRITICAL_DAYS", tlsOpts.CriticalDays)
+		var onCert func(models.Service, *models.CertStatus, models.CertStatus)
+		if alerts != nil {
+			onCert = func(svc models.Service, prev *models.CertStatus, cur models.CertStatus) {
+				if mon.InMaintenance(svc) == nil {
+					alerts.ObserveCert(svc, prev, cur)
+				}
+			}
+		}
+		certs := tlscheck.NewChecker(registry, tlsOpts, onCert)
+		go certs.Run(config.GetEnvDuration("TLS_CHECK_INTERVAL", 6*time.Hour))
+	}
+
+	// DNS records of the public hostnames
+	if config.GetEnv("DNS_CHECK_ENABLED", "true") == "true" {
+		dnsOpts := dnscheck.Options{
+			Resolvers:     config.GetEnvList("DNS_RESOLVERS"),
+			ExpectedIPs:   config.GetEnvList("DNS_EXPECTED_IPS"),
+			ExpectedCNAME: config.GetEnv("DNS_EXPECTED_CNAME", ""),
+		}
+		dns := dnscheck.NewChecker(registry, dnsOpts)
+		go dns.Run(config.GetEnvDuration("DNS_CHECK_INTERVAL", 15*time.Minute))
+	}
+
+	// 5. Initialize Handlers
 	handler := api.NewHandler(registry, mon)
+	handler.Reloader = reloader
+	handler.Network = auditor
+	handler.Audit = auditLog
+	handler.Maintenance = windows
+
+	// Public status page (config/statuspage.json)
+	if pageCfg, err := config.LoadStatusPage(); err != nil {
+		log.Printf("Status page disabled: %v", err)
+	} else {
+		handler.StatusPage = statuspage.New(pageCfg, registry, store, incidents)
+	}
 
-	// 5. Setup Routes
+	// 6. Setup Routes
 	mux := http.NewServeMux()
 
 	// API
 	mux.HandleFunc("/api/services", handler.HandleListServices)
+	mux.HandleFunc("/api/services/", handler.HandleServiceRoutes)
 	mux.HandleFunc("/api/stats", handler.HandleStats)
 	mux.HandleFunc("/api/categories", handler.HandleCategories)
+	mux.HandleFunc("/api/categories/", handler.HandleCategoryRoutes)
+	mux.HandleFunc("/api/pauses", handler.HandlePauses)
+	mux.HandleFunc("/api/incidents", handler.HandleIncidents)
+	mux.HandleFunc("/api/incidents/", handler.HandleIncidentRoutes)
+	mux.HandleFunc("/api/correlations", handler.HandleCorrelations)
+	mux.HandleFunc("/api/graph", handler.HandleGraph)
 	mux.HandleFunc("/api/test/", handler.HandleManualTest)
 	mux.HandleFunc("/api/test-category/", handler.HandleCategoryTest)
 	mux.HandleFunc("/api/events", handler.HandleEvents)
 	mux.HandleFunc("/api/refresh", handler.HandleRefresh)
 	mux.HandleFunc("/api/compliance", handler.HandleCompliance)
+	mux.HandleFunc("/api/sla", handler.HandleSLA)
+	mux.HandleFunc("/api/scheduler", handler.HandleScheduler)
+	mux.HandleFunc("/api/maintenance", handler.HandleMaintenance)
+	mux.HandleFunc("/api/maintenance/", handler.HandleMaintenanceWindow)
+	mux.HandleFunc("/api/admin/reload", handler.HandleReload)
+	mux.HandleFunc("/api/admin/audit", handler.HandleAuditLog)
+	mux.HandleFunc("/api/network", handler.HandleNetwork)
+	mux.HandleFunc("/api/network/connect/", handler.HandleNetworkConnect)
+
+	// Public status page
+	mux.HandleFunc("/status", handler.HandleStatusPage)
+	mux.HandleFunc("/status/", handler.HandleStatusPage)
+
+	// Prometheus
+	mux.HandleFunc("/metrics", handler.HandleMetrics)
 
 	// System Health
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -69,6 +284,24 @@ func main() {
 	fs := http.FileServer(http.Dir("./frontend"))
 	mux.Handle("/", fs)
 
+	// Status page only, for exposing to customers without the dashboard and its API
+	if statusPort := config.GetEnv("STATUS_PAGE_PORT", ""); statusPort != "" && handler.StatusPage != nil {
+		public := http.NewServeMux()
+		public.HandleFunc("/status", handler.HandleStatusPage)
+		public.HandleFunc("/status/", handler.HandleStatusPage)
+		public.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			http.Redirect(w, r, "/status", http.StatusFound)
+		})
+		go func() {
+			log.Printf("Serving status page on port %s", statusPort)
+			log.Fatal(http.ListenAndServe("0.0.0.0:"+statusPort, public))
+		}()
+	}
+
 	log.Printf("Starting Services Dashboard v%s on port %s", version, port)
 	log.Fatal(http.ListenAndServe("0.0.0.0:"+port, mux))
 }
@@ -244,7 +244,12 @@ class ServicesDashboard {
 
     renderServiceCard(svc) {
         const statusClass = svc.status || 'unknown';
-        const responseTimeClass = this.getResponseTimeClass(svc.response_ms);
+        // Prefer the passing request's latency over the wall time of the whole check
+        const latencyMs = svc.timings ? svc.timings.total_ms : svc.response_ms;
+        const latencyTitle = svc.timings
+            ? `title="DNS ${svc.timings.dns_ms}ms · connect ${svc.timings.connect_ms}ms · TLS ${svc.timings.tls_ms}ms · TTFB ${svc.timings.ttfb_ms}ms (check took ${svc.response_ms}ms)"`
+            : '';
+        const responseTimeClass = this.getResponseTimeClass(latencyMs);
         const healthHistoryHtml = this.renderHealthHistory(svc.health_history || []);
         const lastCheckedTime = svc.last_checked ? new Date(svc.last_checked).toLocaleTimeString() : '--';
         const testErrorTitle = svc.test_error ? `title="${svc.test_error}"` : '';
@@ -255,7 +260,7 @@ class ServicesDashboard {
                     <h3 class="service-name">${svc.display_name}</h3>
                     <div class="status-indicator ${statusClass}">
                         <span class="dot"></span>
-                        ${statusClass.charAt(0).toUpperCase() + statusClass.slice(1)}
+                        ${statusClass.charAt(0).toUpperCase() + statusClass.slice(1).replace('_', ' ')}
                     </div>
                 </div>
                 
@@ -265,14 +270,20 @@ class ServicesDashboard {
                     <span class="meta-tag category">${svc.category}</span>
                     <span class="meta-tag version">${svc.version ? 'v' + svc.version : 'Unknown'}</span>
                     <span class="meta-tag port">:${svc.port}</span>
+                    ${svc.root_cause && svc.root_cause !== 'none' ? `<span class="meta-tag root-cause" title="${(svc.diagnosis && svc.diagnosis.summary) || svc.last_error || ''}">${svc.root_cause.replace('_', ' ')}</span>` : ''}
+                    ${this.renderCertTag(svc.tls)}
+                    ${this.renderDNSTag(svc.dns)}
+                    ${svc.paused ? `<span class="meta-tag paused" title="Paused by ${svc.paused.paused_by || 'unknown'}${svc.paused.reason ? ': ' + svc.paused.reason : ''}${svc.paused.until ? ' until ' + svc.paused.until : ''}">⏸ paused</span>` : ''}
+                    ${svc.maintenance ? `<span class="meta-tag maintenance" title="Maintenance window ${svc.maintenance}">🛠 maintenance</span>` : ''}
+                    ${svc.network_status === 'missing' ? `<span class="meta-tag network-missing" title="Container is not attached to the Docker network">⚠ network</span>` : ''}
                     ${(svc.tags || []).slice(0, 2).map(tag =>
             `<span class="meta-tag">${tag}</span>`
         ).join('')}
                 </div>
                 
                 <div class="service-timing">
-                    ${svc.response_ms > 0 ? `
-                        <span class="response-time ${responseTimeClass}">${svc.response_ms}ms</span>
+                    ${latencyMs > 0 ? `
+                        <span class="response-time ${responseTimeClass}" ${latencyTitle}>${latencyMs}ms</span>
                     ` : ''}
                     <span class="last-check">Checked: ${lastCheckedTime}</span>
                 </div>
@@ -300,7 +311,7 @@ class ServicesDashboard {
     renderHealthHistory(history) {
         if (!history || history.length === 0) return '';
         const dots = history.map(status =>
-            `<span class="history-dot ${status === 'healthy' ? 'healthy' : 'unhealthy'}" title="${status}"></span>`
+            `<span class="history-dot ${['healthy', 'degraded'].includes(status) ? status : 'unhealthy'}" title="${status}"></span>`
         ).join('');
         return `<div class="health-history" title="Last ${history.length} health checks">${dots}</div>`;
     }
@@ -357,6 +368,21 @@ class ServicesDashboard {
         }
     }
 
+    renderCertTag(certs) {
+        // Only certificates that need attention get a tag
+        const bad = (certs || []).filter(c => c.status !== 'ok' && c.status !== 'error');
+        if (bad.length === 0) return '';
+        const c = bad.reduce((a, b) => (a.days_remaining <= b.days_remaining ? a : b));
+        const label = c.status === 'invalid' ? 'cert invalid' : c.status === 'expired' ? 'cert expired' : `cert ${c.days_remaining}d`;
+        return `<span class="meta-tag cert-${c.status}" title="${c.host}: ${c.issuer || ''} until ${c.not_after}">🔒 ${label}</span>`;
+    }
+
+    renderDNSTag(records) {
+        const bad = (records || []).find(r => r.status === 'missing' || r.status === 'drift');
+        if (!bad) return '';
+        return `<span class="meta-tag dns-${bad.status}" title="${bad.host}: ${bad.reason || ''}">DNS ${bad.status}</span>`;
+    }
+
     getResponseTimeClass(ms) {
         if (!ms) return '';
         if (ms < 100) return 'fast';
@@ -381,6 +407,10 @@ class ServicesDashboard {
             try {
                 const data = JSON.parse(event.data);
                 if (data.type === 'connected') return;
+                if (data.type === 'service_added' || data.type === 'service_removed') {
+                    this.handleRegistryChange();
+                    return;
+                }
                 this.handleUpdate(data);
             } catch (e) {
                 console.error('SSE Parse Error', e);
@@ -393,6 +423,13 @@ class ServicesDashboard {
         };
     }
 
+    async handleRegistryChange() {
+        // Services were added or removed by a config reload
+        await this.fetchServices();
+        await this.fetchStats();
+        this.render();
+    }
+
     handleUpdate(update) {
         const index = this.services.findIndex(s => s.id === update.id);
         if (index !== -1) {
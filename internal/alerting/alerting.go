@@ -0,0 +1,422 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Event kinds
+const (
+	KindDown        = "down"
+	KindRecovered   = "recovered"
+	KindCircuitOpen = "circuit_open"
+	KindFlapping    = "flapping"
+	KindCertificate = "certificate" // TLS certificate state changed (From/To are certificate states)
+)
+
+// Event is a single alert about a service
+type Event struct {
+	Kind      string    `json:"kind"`
+	ServiceID string    `json:"service_id"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	Tags      []string  `json:"tags,omitempty"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Error     string    `json:"error,omitempty"`
+	URL       string    `json:"url,omitempty"` // Deep link to the service detail view
+	Time      time.Time `json:"time"`
+}
+
+// Summary renders the event as a one-line message
+func (e Event) Summary() string {
+	if e.URL != "" {
+		return e.headline() + " " + e.URL
+	}
+	return e.headline()
+}
+
+func (e Event) headline() string {
+	name := e.Name
+	if name == "" {
+		name = e.ServiceID
+	}
+	switch e.Kind {
+	case KindDown:
+		msg := fmt.Sprintf("🔴 %s is DOWN (%s -> %s)", name, e.From, e.To)
+		if e.Error != "" {
+			msg += ": " + e.Error
+		}
+		return msg
+	case KindRecovered:
+		return fmt.Sprintf("🟢 %s RECOVERED (%s -> %s)", name, e.From, e.To)
+	case KindCircuitOpen:
+		return fmt.Sprintf("⚡ %s circuit breaker OPEN: %s", name, e.Error)
+	case KindFlapping:
+		return fmt.Sprintf("🟠 %s is FLAPPING, alerts suppressed until it settles", name)
+	case KindCertificate:
+		if e.To == models.CertOK {
+			return fmt.Sprintf("🔒 %s certificate OK again: %s", name, e.Error)
+		}
+		return fmt.Sprintf("🔒 %s certificate %s: %s", name, strings.ToUpper(e.To), e.Error)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", name, e.Kind, e.From, e.To)
+}
+
+// Route sends matching events to a set of notifiers.
+// Empty matcher lists match everything.
+type Route struct {
+	Services   []string `json:"services,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Events     []string `json:"events,omitempty"`
+	Notifiers  []string `json:"notifiers"`
+}
+
+func (r Route) matches(ev Event) bool {
+	return matchAny(r.Services, ev.ServiceID) &&
+		matchAny(r.Categories, ev.Category) &&
+		matchAny(r.Events, ev.Kind) &&
+		(len(r.Tags) == 0 || intersects(r.Tags, ev.Tags))
+}
+
+// Config is the content of config/alerts.json
+type Config struct {
+	Notifiers       []NotifierConfig `json:"notifiers"`
+	Routes          []Route          `json:"routes"`
+	DedupWindow     models.Duration  `json:"dedup_window"`      // Repeat of the same event kind is dropped within this window
+	FlapWindow      models.Duration  `json:"flap_window"`       // Window used to count transitions
+	FlapThreshold   int              `json:"flap_threshold"`    // Transitions within FlapWindow that mark a service as flapping
+	NotifyOnStartup bool             `json:"notify_on_startup"` // Alert on the first check after start (unknown -> unhealthy)
+	DashboardURL    string           `json:"dashboard_url"`     // Public base URL used to link events to /api/services/{id}
+}
+
+// serviceState tracks what has been sent for one service
+type serviceState struct {
+	lastSent    map[string]time.Time
+	down        bool // A down or circuit alert was sent and not yet recovered
+	flapping    bool
+	transitions []time.Time
+	last        Event // Latest observed transition, reported once flapping settles
+	settleGen   int   // Identifies the pending settle timer; older timers do nothing
+}
+
+// Manager turns status transitions into routed, deduplicated notifications
+type Manager struct {
+	cfg       Config
+	notifiers map[string]Notifier
+	mu        sync.Mutex
+	states    map[string]*serviceState
+}
+
+// NewManager builds the notifiers and applies defaults to cfg
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.DedupWindow <= 0 {
+		cfg.DedupWindow = models.Duration(15 * time.Minute)
+	}
+	if cfg.FlapWindow <= 0 {
+		cfg.FlapWindow = models.Duration(10 * time.Minute)
+	}
+	if cfg.FlapThreshold <= 0 {
+		cfg.FlapThreshold = 4
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	m := &Manager{
+		cfg:       cfg,
+		notifiers: make(map[string]Notifier),
+		states:    make(map[string]*serviceState),
+	}
+	for _, nc := range cfg.Notifiers {
+		n, err := NewNotifier(nc, client)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := m.notifiers[n.Name()]; dup {
+			return nil, fmt.Errorf("duplicate notifier name %q", n.Name())
+		}
+		m.notifiers[n.Name()] = n
+	}
+	for i, r := range cfg.Routes {
+		for _, name := range r.Notifiers {
+			if _, ok := m.notifiers[name]; !ok {
+				return nil, fmt.Errorf("route %d references unknown notifier %q", i, name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Observe records a status transition of svc and dispatches the resulting alerts.
+// circuitTripped reports that the circuit breaker opened on this check.
+func (m *Manager) Observe(svc models.Service, from, to, errMsg string, circuitTripped bool, at time.Time) {
+	base := Event{
+		ServiceID: svc.ID,
+		Name:      svc.DisplayName,
+		Category:  svc.Category,
+		Tags:      svc.Tags,
+		From:      from,
+		To:        to,
+		Error:     errMsg,
+		Time:      at,
+	}
+	if base.Name == "" {
+		base.Name = svc.Name
+	}
+	if m.cfg.DashboardURL != "" {
+		base.URL = strings.TrimRight(m.cfg.DashboardURL, "/") + "/api/services/" + url.PathEscape(svc.ID)
+	}
+
+	for _, ev := range m.evaluate(base, circuitTripped) {
+		m.dispatch(ev)
+	}
+}
+
+// ObserveCert alerts when the certificate of one of svc's public hosts changes state.
+// Handshake errors are left to the regular down alerts; prev is nil on the first inspection.
+func (m *Manager) ObserveCert(svc models.Service, prev *models.CertStatus, cur models.CertStatus) {
+	if cur.Status == models.CertError {
+		return
+	}
+	from := ""
+	if prev != nil {
+		from = prev.Status
+	}
+	if from == cur.Status || (cur.Status == models.CertOK && (from == "" || from == models.CertError)) {
+		return
+	}
+
+	detail := fmt.Sprintf("%s expires %s (%d days)", cur.Host, cur.NotAfter.Format("2006-01-02"), cur.DaysRemaining)
+	if cur.HostnameMismatch {
+		detail += ", hostname not in certificate"
+	}
+	if cur.ChainError != "" {
+		detail += ", " + cur.ChainError
+	}
+
+	ev := Event{
+		Kind:      KindCertificate,
+		ServiceID: svc.ID,
+		Name:      svc.DisplayName,
+		Category:  svc.Category,
+		Tags:      svc.Tags,
+		From:      from,
+		To:        cur.Status,
+		Error:     detail,
+		Time:      cur.CheckedAt,
+	}
+	if ev.Name == "" {
+		ev.Name = svc.Name
+	}
+	if m.cfg.DashboardURL != "" {
+		ev.URL = strings.TrimRight(m.cfg.DashboardURL, "/") + "/api/services/" + url.PathEscape(svc.ID)
+	}
+	m.dispatch(ev)
+}
+
+// evaluate applies startup, flap and dedup rules and returns the events to send
+func (m *Manager) evaluate(base Event, circuitTripped bool) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.states[base.ServiceID]
+	if !ok {
+		st = &serviceState{lastSent: make(map[string]time.Time)}
+		m.states[base.ServiceID] = st
+	}
+
+	firstCheck := base.From == "" || base.From == models.StatusUnknown
+	changed := base.From != base.To
+	// Only flips between up and down count towards flapping, not healthy <-> degraded
+	flipped := models.StatusCounted(base.From) && models.StatusCounted(base.To) &&
+		models.StatusUp(base.From) != models.StatusUp(base.To)
+
+	var events []Event
+	st.last = base
+
+	if flipped && !firstCheck {
+		st.transitions = append(st.transitions, base.Time)
+		cutoff := base.Time.Add(-m.cfg.FlapWindow.D())
+		kept := st.transitions[:0]
+		for _, t := range st.transitions {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		st.transitions = kept
+
+		if len(st.transitions) >= m.cfg.FlapThreshold {
+			if !st.flapping {
+				st.flapping = true
+				ev := base
+				ev.Kind = KindFlapping
+				events = append(events, ev)
+			}
+			m.deferSettle(base.ServiceID, st)
+			return events
+		}
+	}
+	// While flapping only a tripping breaker is reported; the settle timer
+	// sends whatever else is still pending
+	if st.flapping && !circuitTripped {
+		return events
+	}
+
+	switch {
+	case circuitTripped:
+		ev := base
+		ev.Kind = KindCircuitOpen
+		if m.allow(st, ev) {
+			st.down = true
+			events = append(events, ev)
+		}
+	case changed && models.StatusUp(base.To):
+		if st.down {
+			ev := base
+			ev.Kind = KindRecovered
+			st.down = false
+			delete(st.lastSent, KindDown)
+			delete(st.lastSent, KindCircuitOpen)
+			events = append(events, ev)
+		}
+	case changed && base.To == models.StatusUnhealthy:
+		if firstCheck && !m.cfg.NotifyOnStartup {
+			break
+		}
+		ev := base
+		ev.Kind = KindDown
+		if m.allow(st, ev) {
+			st.down = true
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// deferSettle (re)starts the timer that ends flapping of a service once it
+// made no flip for a whole flap window. Called with m.mu held.
+func (m *Manager) deferSettle(id string, st *serviceState) {
+	st.settleGen++
+	gen := st.settleGen
+	time.AfterFunc(m.cfg.FlapWindow.D(), func() { m.settle(id, gen) })
+}
+
+// settle ends flapping of a service and sends the alert its current state
+// still calls for: down when it stayed down, recovered when a down alert
+// was sent before it started flapping
+func (m *Manager) settle(id string, gen int) {
+	m.mu.Lock()
+	st, ok := m.states[id]
+	if !ok || !st.flapping || st.settleGen != gen {
+		m.mu.Unlock()
+		return
+	}
+	st.flapping = false
+	st.transitions = nil
+
+	var events []Event
+	ev := st.last
+	ev.Time = time.Now()
+	switch {
+	case models.StatusUp(ev.To):
+		if st.down {
+			ev.Kind = KindRecovered
+			st.down = false
+			delete(st.lastSent, KindDown)
+			delete(st.lastSent, KindCircuitOpen)
+			events = append(events, ev)
+		}
+	case ev.To == models.StatusUnhealthy || ev.To == models.StatusCircuitOpen:
+		ev.Kind = KindDown
+		if !st.down && m.allow(st, ev) {
+			st.down = true
+			events = append(events, ev)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, ev := range events {
+		m.dispatch(ev)
+	}
+}
+
+// allow implements deduplication: the same kind is sent at most once per window
+func (m *Manager) allow(st *serviceState, ev Event) bool {
+	if last, ok := st.lastSent[ev.Kind]; ok && ev.Time.Sub(last) < m.cfg.DedupWindow.D() {
+		return false
+	}
+	st.lastSent[ev.Kind] = ev.Time
+	return true
+}
+
+// dispatch routes the event and delivers it asynchronously
+func (m *Manager) dispatch(ev Event) {
+	targets := m.route(ev)
+
+	for _, n := range targets {
+		go func(n Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			if err := n.Notify(ctx, ev); err != nil {
+				log.Printf("Alert %s for %s via %s failed: %v", ev.Kind, ev.ServiceID, n.Name(), err)
+			}
+		}(n)
+	}
+}
+
+// route returns the notifiers selected by the routing rules
+func (m *Manager) route(ev Event) []Notifier {
+	if len(m.cfg.Routes) == 0 {
+		all := make([]Notifier, 0, len(m.notifiers))
+		for _, n := range m.notifiers {
+			all = append(all, n)
+		}
+		return all
+	}
+
+	seen := make(map[string]bool)
+	var targets []Notifier
+	for _, r := range m.cfg.Routes {
+		if !r.matches(ev) {
+			continue
+		}
+		for _, name := range r.Notifiers {
+			if n, ok := m.notifiers[name]; ok && !seen[name] {
+				seen[name] = true
+				targets = append(targets, n)
+			}
+		}
+	}
+	return targets
+}
+
+func matchAny(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package alerting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// webhookSink is a local webhook receiver collecting the events it is sent
+func webhookSink(t *testing.T) (string, <-chan Event) {
+	t.Helper()
+	events := make(chan Event, 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev Event
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		events <- ev
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, events
+}
+
+func newTestManager(t *testing.T, cfg Config) (*Manager, <-chan Event) {
+	t.Helper()
+	url, events := webhookSink(t)
+	cfg.Notifiers = append(cfg.Notifiers, NotifierConfig{Name: "hook", Type: "webhook", URL: url})
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, events
+}
+
+// expect waits for the next events and checks their kinds
+func expect(t *testing.T, events <-chan Event, kinds ...string) {
+	t.Helper()
+	for _, kind := range kinds {
+		select {
+		case ev := <-events:
+			if ev.Kind != kind {
+				t.Fatalf("got %s event, want %s", ev.Kind, kind)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s event", kind)
+		}
+	}
+}
+
+// expectNone checks that no event arrives for a while
+func expectNone(t *testing.T, events <-chan Event, wait time.Duration) {
+	t.Helper()
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected %s event", ev.Kind)
+	case <-time.After(wait):
+	}
+}
+
+var testService = models.Service{ID: "svc", Name: "svc", Category: "tools"}
+
+func TestDownAndRecovered(t *testing.T) {
+	m, events := newTestManager(t, Config{})
+	now := time.Now()
+
+	m.Observe(testService, models.StatusUnknown, models.StatusHealthy, "", false, now)
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "refused", false, now.Add(time.Minute))
+	expect(t, events, KindDown)
+	m.Observe(testService, models.StatusUnhealthy, models.StatusHealthy, "", false, now.Add(2*time.Minute))
+	expect(t, events, KindRecovered)
+	expectNone(t, events, 50*time.Millisecond)
+}
+
+func TestStartupIsSilentByDefault(t *testing.T) {
+	m, events := newTestManager(t, Config{})
+	m.Observe(testService, models.StatusUnknown, models.StatusUnhealthy, "refused", false, time.Now())
+	expectNone(t, events, 50*time.Millisecond)
+}
+
+func TestDedupWindow(t *testing.T) {
+	m, events := newTestManager(t, Config{DedupWindow: models.Duration(time.Hour), FlapThreshold: 100})
+	now := time.Now()
+
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", true, now)
+	expect(t, events, KindCircuitOpen)
+	m.Observe(testService, models.StatusCircuitOpen, models.StatusCircuitOpen, "", true, now.Add(time.Minute))
+	expectNone(t, events, 50*time.Millisecond)
+}
+
+func TestFlappingSettlesDown(t *testing.T) {
+	window := 200 * time.Millisecond
+	m, events := newTestManager(t, Config{FlapWindow: models.Duration(window), FlapThreshold: 3})
+	now := time.Now()
+
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	expect(t, events, KindDown)
+	m.Observe(testService, models.StatusUnhealthy, models.StatusHealthy, "", false, now)
+	expect(t, events, KindRecovered)
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	expect(t, events, KindFlapping)
+	m.Observe(testService, models.StatusUnhealthy, models.StatusHealthy, "", false, now)
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	expectNone(t, events, window/2)
+
+	// It stays down: the pending down alert goes out once the window passes
+	expect(t, events, KindDown)
+	m.mu.Lock()
+	flapping := m.states[testService.ID].flapping
+	m.mu.Unlock()
+	if flapping {
+		t.Error("still flapping after settling")
+	}
+
+	m.Observe(testService, models.StatusUnhealthy, models.StatusHealthy, "", false, time.Now())
+	expect(t, events, KindRecovered)
+}
+
+func TestFlappingSettlesUpWithoutAlert(t *testing.T) {
+	window := 100 * time.Millisecond
+	m, events := newTestManager(t, Config{FlapWindow: models.Duration(window), FlapThreshold: 2})
+	now := time.Now()
+
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	expect(t, events, KindDown)
+	m.Observe(testService, models.StatusUnhealthy, models.StatusHealthy, "", false, now)
+	expect(t, events, KindFlapping)
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	m.Observe(testService, models.StatusUnhealthy, models.StatusHealthy, "", false, now)
+
+	// The down alert sent before flapping is answered by a recovery
+	expect(t, events, KindRecovered)
+	expectNone(t, events, 2*window)
+}
+
+func TestRoutes(t *testing.T) {
+	dbURL, dbEvents := webhookSink(t)
+	allURL, allEvents := webhookSink(t)
+	m, err := NewManager(Config{
+		Notifiers: []NotifierConfig{
+			{Name: "db", Type: "webhook", URL: dbURL},
+			{Name: "all", Type: "slack", URL: allURL},
+		},
+		Routes: []Route{
+			{Categories: []string{"databases"}, Notifiers: []string{"db"}},
+			{Events: []string{KindDown}, Notifiers: []string{"all"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	m.Observe(testService, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	select {
+	case <-allEvents:
+	case <-time.After(2 * time.Second):
+		t.Fatal("down event not routed to the catch-all notifier")
+	}
+	expectNone(t, dbEvents, 50*time.Millisecond)
+
+	db := models.Service{ID: "pg", Category: "databases"}
+	m.Observe(db, models.StatusHealthy, models.StatusUnhealthy, "", false, now)
+	expect(t, dbEvents, KindDown)
+}
+
+func TestUnknownNotifierInRoute(t *testing.T) {
+	_, err := NewManager(Config{Routes: []Route{{Notifiers: []string{"missing"}}}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown notifier")
+	}
+}
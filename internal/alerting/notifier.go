@@ -0,0 +1,243 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Notifier delivers alert events to an external channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, ev Event) error
+}
+
+// NotifierConfig describes one notifier in alerts.json.
+// Which fields are used depends on Type: webhook, slack, smtp or telegram.
+type NotifierConfig struct {
+	Name    string            `json:"name"`
+	Type    string            `json:"type"`
+	URL     string            `json:"url,omitempty"`     // webhook, slack; API base for telegram
+	Headers map[string]string `json:"headers,omitempty"` // webhook
+	Channel string            `json:"channel,omitempty"` // slack
+	Token   string            `json:"token,omitempty"`   // telegram bot token
+	ChatID  string            `json:"chat_id,omitempty"` // telegram
+	Addr    string            `json:"addr,omitempty"`    // smtp host:port
+	From    string            `json:"from,omitempty"`    // smtp
+	To      []string          `json:"to,omitempty"`      // smtp
+	User    string            `json:"username,omitempty"`
+	Pass    string            `json:"password,omitempty"`
+}
+
+// NewNotifier builds a notifier from its config
+func NewNotifier(cfg NotifierConfig, client *http.Client) (Notifier, error) {
+	if cfg.Name == "" {
+		cfg.Name = cfg.Type
+	}
+	switch cfg.Type {
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("notifier %s: url is required", cfg.Name)
+		}
+		return &WebhookNotifier{name: cfg.Name, URL: cfg.URL, Headers: cfg.Headers, client: client}, nil
+	case "slack":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("notifier %s: url is required", cfg.Name)
+		}
+		return &SlackNotifier{name: cfg.Name, WebhookURL: cfg.URL, Channel: cfg.Channel, client: client}, nil
+	case "telegram":
+		if cfg.Token == "" || cfg.ChatID == "" {
+			return nil, fmt.Errorf("notifier %s: token and chat_id are required", cfg.Name)
+		}
+		base := cfg.URL
+		if base == "" {
+			base = "https://api.telegram.org"
+		}
+		return &TelegramNotifier{name: cfg.Name, APIBase: strings.TrimRight(base, "/"), Token: cfg.Token, ChatID: cfg.ChatID, client: client}, nil
+	case "smtp":
+		if cfg.Addr == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("notifier %s: addr, from and to are required", cfg.Name)
+		}
+		return &SMTPNotifier{name: cfg.Name, Addr: cfg.Addr, From: cfg.From, To: cfg.To, Username: cfg.User, Password: cfg.Pass}, nil
+	default:
+		return nil, fmt.Errorf("notifier %s: unknown type %q", cfg.Name, cfg.Type)
+	}
+}
+
+// WebhookNotifier POSTs the event as JSON
+type WebhookNotifier struct {
+	name    string
+	URL     string
+	Headers map[string]string
+	client  *http.Client
+}
+
+func (n *WebhookNotifier) Name() string { return n.name }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, ev Event) error {
+	return postJSON(ctx, n.client, n.URL, n.Headers, ev)
+}
+
+// SlackNotifier posts to a Slack-compatible incoming webhook
+type SlackNotifier struct {
+	name       string
+	WebhookURL string
+	Channel    string
+	client     *http.Client
+}
+
+func (n *SlackNotifier) Name() string { return n.name }
+
+func (n *SlackNotifier) Notify(ctx context.Context, ev Event) error {
+	payload := map[string]string{"text": ev.Summary()}
+	if n.Channel != "" {
+		payload["channel"] = n.Channel
+	}
+	return postJSON(ctx, n.client, n.WebhookURL, nil, payload)
+}
+
+// TelegramNotifier sends messages through a Telegram-style bot API
+type TelegramNotifier struct {
+	name    string
+	APIBase string
+	Token   string
+	ChatID  string
+	client  *http.Client
+}
+
+func (n *TelegramNotifier) Name() string { return n.name }
+
+func (n *TelegramNotifier) Notify(ctx context.Context, ev Event) error {
+	url := fmt.Sprintf("%s/bot%s/sendMessage", n.APIBase, n.Token)
+	return postJSON(ctx, n.client, url, nil, map[string]string{
+		"chat_id": n.ChatID,
+		"text":    ev.Summary(),
+	})
+}
+
+// SMTPNotifier sends a plain-text email per event
+type SMTPNotifier struct {
+	name     string
+	Addr     string
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+func (n *SMTPNotifier) Name() string { return n.name }
+
+// smtpTimeout bounds a whole SMTP delivery when ctx has no earlier deadline
+const smtpTimeout = 30 * time.Second
+
+func (n *SMTPNotifier) Notify(ctx context.Context, ev Event) error {
+	host, _, err := net.SplitHostPort(n.Addr)
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", oneLine(ev.Summary())))
+	fmt.Fprintf(&msg, "Date: %s\r\n", ev.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Service: %s (%s)\r\n", ev.ServiceID, ev.Category)
+	fmt.Fprintf(&msg, "Event: %s\r\n", ev.Kind)
+	fmt.Fprintf(&msg, "Status: %s -> %s\r\n", ev.From, ev.To)
+	if ev.Error != "" {
+		fmt.Fprintf(&msg, "Error: %s\r\n", oneLine(ev.Error))
+	}
+	fmt.Fprintf(&msg, "Time: %s\r\n", ev.Time.Format(time.RFC3339))
+
+	// smtp.SendMail has no deadline, so dial and talk to the server here
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// oneLine replaces line breaks so a value cannot start a new header or line
+func oneLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package alerting
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStandIn is a minimal local SMTP server accepting every message
+type smtpStandIn struct {
+	ln       net.Listener
+	messages chan string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStandIn{ln: ln, messages: make(chan string, 10)}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"), strings.HasPrefix(cmd, "RSET"):
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.messages <- data.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	n := &SMTPNotifier{name: "mail", Addr: srv.ln.Addr().String(), From: "dash@example.com", To: []string{"ops@example.com"}}
+
+	ev := Event{
+		Kind:      KindDown,
+		ServiceID: "svc",
+		Name:      "svc",
+		From:      "healthy",
+		To:        "unhealthy",
+		Error:     "boom\r\nBcc: attacker@example.com",
+		Time:      time.Now(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Notify(ctx, ev); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg string
+	select {
+	case msg = <-srv.messages:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+	headers := msg[:strings.Index(msg, "\r\n\r\n")]
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Fatalf("injected header in %q", headers)
+		}
+		if strings.HasPrefix(line, "Subject: ") {
+			subject, err := new(mime.WordDecoder).DecodeHeader(strings.TrimPrefix(line, "Subject: "))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(subject, "svc is DOWN") || !strings.Contains(subject, "boom Bcc:") {
+				t.Errorf("got subject %q", subject)
+			}
+		}
+	}
+}
+
+func TestSMTPNotifierHonoursContext(t *testing.T) {
+	// A server that accepts connections but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	n := &SMTPNotifier{name: "mail", Addr: ln.Addr().String(), From: "a@example.com", To: []string{"b@example.com"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Notify(ctx, Event{Kind: KindDown, Time: time.Now()}); err == nil {
+		t.Fatal("expected an error from a stuck server")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Notify returned after %v", elapsed)
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	var got map[string]string
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(NotifierConfig{Type: "telegram", URL: srv.URL, Token: "T0K", ChatID: "42"}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), Event{Kind: KindRecovered, Name: "svc", From: "unhealthy", To: "healthy"}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/botT0K/sendMessage" || got["chat_id"] != "42" || !strings.Contains(got["text"], "svc RECOVERED") {
+		t.Errorf("got %s %v", path, got)
+	}
+}
+
+func TestWebhookNotifierReportsHTTPErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(NotifierConfig{Type: "webhook", URL: srv.URL}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), Event{Kind: KindDown}); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("got %v, want an HTTP 502 error", err)
+	}
+}
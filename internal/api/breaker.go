@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+)
+
+// HandleServiceBreaker returns the circuit breaker state of /api/services/{id}/breaker
+func (h *Handler) HandleServiceBreaker(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	svc, exists := h.Registry.Get(id)
+	if !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(svc.Breaker)
+}
+
+// HandleBreakerReset closes the circuit breaker of /api/services/{id}/breaker/reset
+// and checks the service right away
+func (h *Handler) HandleBreakerReset(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	prev, ok := h.Monitor.ResetBreaker(id)
+	if !ok {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+	if h.Audit != nil {
+		entry := audit.NewEntry(r, "breaker_reset", id, "was "+prev.State)
+		if err := h.Audit.Record(entry); err != nil {
+			log.Printf("Failed to write audit log: %v", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       id,
+		"previous": prev,
+	})
+}
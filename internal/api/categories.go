@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// CategorySummary is one entry of /api/categories
+type CategorySummary struct {
+	Name           string   `json:"name"`
+	DisplayName    string   `json:"display_name"`
+	Description    string   `json:"description,omitempty"`
+	Owner          string   `json:"owner,omitempty"`
+	PortMin        int      `json:"port_min,omitempty"`
+	PortMax        int      `json:"port_max,omitempty"`
+	Total          int      `json:"total"`
+	Healthy        int      `json:"healthy"`
+	Degraded       int      `json:"degraded"`
+	Unhealthy      int      `json:"unhealthy"` // Includes open circuit breakers
+	Other          int      `json:"other"`     // Maintenance, paused or not checked yet
+	AvgResponseMs  float64  `json:"avg_response_ms"`
+	ComplianceAvg  *float64 `json:"compliance_avg"` // nil until a compliance scan ran
+	OutOfPortRange []string `json:"out_of_port_range,omitempty"`
+}
+
+// categoryAcc accumulates averages while building a CategorySummary
+type categoryAcc struct {
+	CategorySummary
+	ids             []string
+	responseSamples int
+	responseTotal   int64
+}
+
+// HandleCategories returns the categories present in the registry with counts,
+// average latency and compliance, merged with config/categories.json metadata.
+func (h *Handler) HandleCategories(w http.ResponseWriter, req *http.Request) {
+	byName := make(map[string]*categoryAcc)
+	get := func(name string) *categoryAcc {
+		c, ok := byName[name]
+		if !ok {
+			c = &categoryAcc{CategorySummary: CategorySummary{Name: name, DisplayName: name}}
+			byName[name] = c
+		}
+		return c
+	}
+
+	// Configured categories are listed even when empty
+	categories := h.Registry.Categories()
+	for name, meta := range categories {
+		c := get(name)
+		if meta.DisplayName != "" {
+			c.DisplayName = meta.DisplayName
+		}
+		c.Description = meta.Description
+		c.Owner = meta.Owner
+		c.PortMin = meta.PortMin
+		c.PortMax = meta.PortMax
+	}
+	for _, s := range h.Registry.GetAll() {
+		c := get(s.Category)
+		c.ids = append(c.ids, s.ID)
+		c.Total++
+		switch s.Status {
+		case models.StatusHealthy:
+			c.Healthy++
+		case models.StatusDegraded:
+			c.Degraded++
+		case models.StatusUnhealthy, models.StatusCircuitOpen:
+			c.Unhealthy++
+		default:
+			c.Other++
+		}
+		if !s.LastChecked.IsZero() {
+			c.responseSamples++
+			c.responseTotal += s.ResponseMs
+		}
+		if meta, ok := categories[s.Category]; ok && s.Port > 0 && !meta.InPortRange(s.Port) {
+			c.OutOfPortRange = append(c.OutOfPortRange, s.ID)
+		}
+	}
+
+	list := make([]CategorySummary, 0, len(byName))
+	for _, c := range byName {
+		scanned, score := 0, 0
+		for _, id := range c.ids {
+			if report, ok := h.Monitor.Compliance(id); ok {
+				scanned++
+				score += report.TotalScore
+			}
+		}
+		if scanned > 0 {
+			avg := float64(score) / float64(scanned)
+			c.ComplianceAvg = &avg
+		}
+		if c.responseSamples > 0 {
+			c.AvgResponseMs = float64(c.responseTotal) / float64(c.responseSamples)
+		}
+		sort.Strings(c.OutOfPortRange)
+		list = append(list, c.CategorySummary)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
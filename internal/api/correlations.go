@@ -0,0 +1,23 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleCorrelations returns groups of services failing at the same time for
+// a probable common cause: the active ones, largest first, and the recently
+// resolved ones, newest first
+func (h *Handler) HandleCorrelations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	active, resolved := h.Monitor.Correlations()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active":   active,
+		"resolved": resolved,
+	})
+}
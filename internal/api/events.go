@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// ServiceUpdate is one real-time update of a service sent over SSE
+type ServiceUpdate struct {
+	Type        string               `json:"type,omitempty"` // empty for state changes, or service_added / service_removed
+	ServiceID   string               `json:"id"`
+	Status      string               `json:"status"`
+	TestStatus  string               `json:"test_status,omitempty"`
+	TestError   string               `json:"test_error,omitempty"`
+	LastError   string               `json:"last_error,omitempty"`
+	ResponseMs  int64                `json:"response_ms"`
+	Timings     *models.PhaseTimings `json:"timings,omitempty"`
+	RootCause   string               `json:"root_cause,omitempty"`
+	Diagnosis   *models.Diagnosis    `json:"diagnosis,omitempty"`
+	Breaker     *models.BreakerState `json:"breaker,omitempty"`
+	Maintenance string               `json:"maintenance"` // Active window ID, empty when none
+	Paused      *models.Pause        `json:"paused"`      // nil when checks run
+}
+
+// newServiceUpdate converts a registry event into its SSE form
+func newServiceUpdate(ev models.Event) ServiceUpdate {
+	svc := ev.Service
+	switch ev.Kind {
+	case models.EventAdded:
+		return ServiceUpdate{Type: "service_added", ServiceID: svc.ID, Status: svc.Status}
+	case models.EventRemoved:
+		return ServiceUpdate{Type: "service_removed", ServiceID: svc.ID}
+	}
+	return ServiceUpdate{
+		ServiceID:   svc.ID,
+		Status:      svc.Status,
+		TestStatus:  svc.TestStatus,
+		TestError:   svc.TestError,
+		LastError:   svc.LastError,
+		ResponseMs:  svc.ResponseMs,
+		Timings:     svc.Timings,
+		RootCause:   svc.RootCause,
+		Diagnosis:   svc.Diagnosis,
+		Breaker:     &svc.Breaker,
+		Maintenance: svc.Maintenance,
+		Paused:      svc.Paused,
+	}
+}
+
+// HandleEvents streams registry changes as real-time service updates via SSE,
+// together with correlated outages (correlation_opened, correlation_updated,
+// correlation_resolved)
+func (h *Handler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := h.Registry.Subscribe()
+	defer cancel()
+	correlations, cancelCorrelations := h.Monitor.SubscribeCorrelations()
+	defer cancelCorrelations()
+
+	// Send connection established message
+	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
+	flusher.Flush()
+
+	notify := r.Context().Done()
+
+	for {
+		select {
+		case <-notify:
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return // Fell too far behind; the browser reconnects
+			}
+			data, err := json.Marshal(newServiceUpdate(ev))
+			if err == nil {
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			}
+		case ev, ok := <-correlations:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err == nil {
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleGraph returns the declared service dependencies as nodes and edges
+// with their current status. Failing services whose upstream is down are
+// marked impacted. Pass ?category= to keep the services of one category and
+// the services they depend on.
+func (h *Handler) HandleGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	graph := h.Monitor.Graph()
+	if category := r.URL.Query().Get("category"); category != "" {
+		keep := make(map[string]bool)
+		for _, n := range graph.Nodes {
+			if n.Category == category {
+				keep[n.ID] = true
+			}
+		}
+		edges := graph.Edges[:0]
+		for _, e := range graph.Edges {
+			if keep[e.From] {
+				edges = append(edges, e)
+			}
+		}
+		for _, e := range edges {
+			keep[e.To] = true
+		}
+		nodes := graph.Nodes[:0]
+		for _, n := range graph.Nodes {
+			if keep[n.ID] {
+				nodes = append(nodes, n)
+			}
+		}
+		graph.Nodes, graph.Edges = nodes, edges
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
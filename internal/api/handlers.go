@@ -4,34 +4,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/baditaflorin/go_services_dashboard/internal/compliance"
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/config"
+	"github.com/baditaflorin/go_services_dashboard/internal/maintenance"
 	"github.com/baditaflorin/go_services_dashboard/internal/models"
 	"github.com/baditaflorin/go_services_dashboard/internal/monitor"
+	"github.com/baditaflorin/go_services_dashboard/internal/network"
+	"github.com/baditaflorin/go_services_dashboard/internal/statuspage"
 )
 
 // ... existing code ...
 
 // HandleCompliance runs a standardization scan on all services
 func (h *Handler) HandleCompliance(w http.ResponseWriter, r *http.Request) {
-	services := h.Registry.GetAll()
-	reports := make([]compliance.ComplianceReport, 0, len(services))
-
-	client := &http.Client{Timeout: 5 * time.Second}
-
-	for _, svc := range services {
-		report := compliance.Scan(client, svc)
-		reports = append(reports, report)
-	}
+	reports := h.Monitor.ScanCompliance()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(reports)
 }
 
 type Handler struct {
-	Registry *models.Registry
-	Monitor  *monitor.Monitor
+	Registry    *models.Registry
+	Monitor     *monitor.Monitor
+	Reloader    *config.Reloader
+	Network     *network.Auditor
+	Audit       *audit.Log
+	Maintenance *maintenance.Store
+	StatusPage  *statuspage.Page
 }
 
 func NewHandler(r *models.Registry, m *monitor.Monitor) *Handler {
@@ -41,44 +44,159 @@ func NewHandler(r *models.Registry, m *monitor.Monitor) *Handler {
 	}
 }
 
+// HandleListServices returns services matching the query string filters.
+// See ParseServiceQuery for the supported parameters. With ?limit= the
+// response carries X-Total-Count and, when more pages exist, X-Next-Cursor.
 func (h *Handler) HandleListServices(w http.ResponseWriter, req *http.Request) {
-	list := h.Registry.GetAll()
+	query, err := ParseServiceQuery(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, total, next := query.Apply(h.Registry.GetAll())
+
+	if query.Limit > 0 {
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		if next != "" {
+			w.Header().Set("X-Next-Cursor", next)
+			nextURL := *req.URL
+			q := nextURL.Query()
+			q.Set("cursor", next)
+			nextURL.RawQuery = q.Encode()
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", nextURL.RequestURI()))
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(list)
 }
 
+// HandleServiceRoutes dispatches /api/services/{id}/... sub-resources
+func (h *Handler) HandleServiceRoutes(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(r.URL.Path[len("/api/services/"):], "/")
+	parts := strings.SplitN(rest, "/", 2)
+	id := parts[0]
+	if id == "" {
+		http.Error(w, "Missing service ID", http.StatusBadRequest)
+		return
+	}
+	if _, exists := h.Registry.Get(id); !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	sub := ""
+	if len(parts) > 1 {
+		sub = parts[1]
+	}
+
+	switch sub {
+	case "":
+		h.HandleGetService(w, r, id)
+	case "history":
+		h.HandleServiceHistory(w, r, id)
+	case "latency":
+		h.HandleServiceLatency(w, r, id)
+	case "breaker":
+		h.HandleServiceBreaker(w, r, id)
+	case "breaker/reset":
+		h.HandleBreakerReset(w, r, id)
+	case "pause":
+		h.handlePause(w, r, models.PauseService, id)
+	case "resume":
+		h.handleResume(w, r, models.PauseService, id)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
 func (h *Handler) HandleStats(w http.ResponseWriter, req *http.Request) {
 	list := h.Registry.GetAll()
 	total := len(list)
-	healthy := 0
-	unhealthy := 0
-
-	for _, s := range list {
-		if s.Status == "healthy" {
-			healthy++
-		} else if s.Status == "unhealthy" {
-			unhealthy++
+	byStatus := make(map[string]int, len(models.Statuses))
+	for _, st := range models.Statuses {
+		byStatus[st] = 0
+	}
+	up, counted := 0, 0
+	certs := certStats{ByStatus: map[string]int{}}
+	dns := dnsStats{ByStatus: map[string]int{}, Flagged: []string{}}
+
+	for i := range list {
+		s := &list[i]
+		byStatus[s.Status]++
+		if models.StatusCounted(s.Status) {
+			counted++
+			if models.StatusUp(s.Status) {
+				up++
+			}
 		}
+		certs.add(s)
+		dns.add(s)
 	}
 
+	// Services in maintenance, paused or not checked yet are left out of the percentage
 	healthyPercent := 0.0
-	if total > 0 {
-		healthyPercent = (float64(healthy) / float64(total)) * 100
+	if counted > 0 {
+		healthyPercent = (float64(up) / float64(counted)) * 100
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"total":           total,
-		"healthy":         healthy,
-		"unhealthy":       unhealthy,
+		"healthy":         byStatus[models.StatusHealthy],
+		"degraded":        byStatus[models.StatusDegraded],
+		"unhealthy":       byStatus[models.StatusUnhealthy],
+		"circuit_open":    byStatus[models.StatusCircuitOpen],
+		"by_status":       byStatus,
 		"healthy_percent": healthyPercent,
+		"tls":             certs,
+		"dns":             dns,
 	})
 }
 
-func (h *Handler) HandleCategories(w http.ResponseWriter, req *http.Request) {
-	categories := []string{"domains", "security", "recon", "infrastructure", "web_analysis"}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(categories)
+// certStats summarises certificate states across services for /api/stats
+type certStats struct {
+	Checked    int            `json:"checked"`
+	ByStatus   map[string]int `json:"by_status"`
+	NextExpiry *certExpiry    `json:"next_expiry,omitempty"`
+}
+
+// dnsStats summarises public hostname resolution for /api/stats
+type dnsStats struct {
+	Checked  int            `json:"checked"`
+	ByStatus map[string]int `json:"by_status"`
+	Flagged  []string       `json:"flagged"` // Hostnames that are missing or drifting
+}
+
+func (d *dnsStats) add(s *models.Service) {
+	for _, rec := range s.DNS {
+		d.Checked++
+		d.ByStatus[rec.Status]++
+		if rec.Status == models.DNSMissing || rec.Status == models.DNSDrift {
+			d.Flagged = append(d.Flagged, rec.Host)
+		}
+	}
+}
+
+type certExpiry struct {
+	ServiceID     string    `json:"id"`
+	Host          string    `json:"host"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"`
+}
+
+func (c *certStats) add(s *models.Service) {
+	for _, cert := range s.TLS {
+		c.Checked++
+		c.ByStatus[cert.Status]++
+		if cert.NotAfter.IsZero() {
+			continue
+		}
+		if c.NextExpiry == nil || cert.NotAfter.Before(c.NextExpiry.NotAfter) {
+			c.NextExpiry = &certExpiry{ServiceID: s.ID, Host: cert.Host, NotAfter: cert.NotAfter, DaysRemaining: cert.DaysRemaining}
+		}
+	}
 }
 
 func (h *Handler) HandleManualTest(w http.ResponseWriter, r *http.Request) {
@@ -124,7 +242,7 @@ func (h *Handler) HandleCategoryTest(w http.ResponseWriter, r *http.Request) {
 	passed := 0
 
 	for _, svc := range services {
-		if svc.Category == category {
+		if svc.Category == category && svc.Status != models.StatusPaused {
 			status, _, err := h.Monitor.TestActiveLink(svc.ID)
 			if err == nil {
 				tested++
@@ -143,24 +261,28 @@ func (h *Handler) HandleCategoryTest(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleRefresh triggers a full health check of all services
+// HandleRefresh makes every service due for a check now
 func (h *Handler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Trigger async check
-	go h.Monitor.CheckAll()
+	// The scheduler picks the checks up in priority order
+	h.Monitor.CheckAll()
 
 	// Return current stats
 	list := h.Registry.GetAll()
 	total := len(list)
-	healthy := 0
+	up, counted := 0, 0
 
+	// Paused, maintenance and unchecked services are neither healthy nor unhealthy
 	for _, s := range list {
-		if s.Status == "healthy" {
-			healthy++
+		if models.StatusCounted(s.Status) {
+			counted++
+			if models.StatusUp(s.Status) {
+				up++
+			}
 		}
 	}
 
@@ -168,42 +290,7 @@ func (h *Handler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message":   "Refresh triggered",
 		"total":     total,
-		"healthy":   healthy,
-		"unhealthy": total - healthy,
+		"healthy":   up,
+		"unhealthy": counted - up,
 	})
 }
-
-// HandleEvents streams real-time service updates via SSE
-func (h *Handler) HandleEvents(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
-		return
-	}
-
-	ch := h.Monitor.Subscribe()
-	defer h.Monitor.Unsubscribe(ch)
-
-	// Send connection established message
-	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
-	flusher.Flush()
-
-	notify := r.Context().Done()
-
-	for {
-		select {
-		case <-notify:
-			return
-		case update := <-ch:
-			data, err := json.Marshal(update)
-			if err == nil {
-				fmt.Fprintf(w, "data: %s\n\n", data)
-				flusher.Flush()
-			}
-		}
-	}
-}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+	"github.com/baditaflorin/go_services_dashboard/internal/monitor"
+)
+
+func TestRefreshLeavesUncountedOut(t *testing.T) {
+	registry := models.NewRegistry()
+	for id, status := range map[string]string{
+		"up":      models.StatusHealthy,
+		"slow":    models.StatusDegraded,
+		"down":    models.StatusUnhealthy,
+		"paused":  models.StatusPaused,
+		"maint":   models.StatusMaintenance,
+		"unknown": models.StatusUnknown,
+	} {
+		registry.Add(models.Service{ID: id, Status: status})
+	}
+	h := NewHandler(registry, monitor.NewMonitor(registry, nil))
+
+	rec := httptest.NewRecorder()
+	h.HandleRefresh(rec, httptest.NewRequest(http.MethodPost, "/api/refresh", nil))
+	var got struct {
+		Total     int `json:"total"`
+		Healthy   int `json:"healthy"`
+		Unhealthy int `json:"unhealthy"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Total != 6 || got.Healthy != 2 || got.Unhealthy != 1 {
+		t.Errorf("got %+v, want 6 total, 2 healthy, 1 unhealthy", got)
+	}
+}
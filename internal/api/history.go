@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/monitor"
+)
+
+// HandleServiceHistory returns recorded check results for a service.
+// Query params: from, to (RFC3339 or unix seconds, default last 24h; from is
+// clamped to the retention period) and step (Go duration such as "5m"; omitted returns raw checks).
+func (h *Handler) HandleServiceHistory(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	store := h.Monitor.History()
+	if store == nil {
+		http.Error(w, "History is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	now := time.Now()
+	to, err := parseTimeParam(q.Get("to"), now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, err := parseTimeParam(q.Get("from"), to.Add(-24*time.Hour))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from.After(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+	// Nothing older than the retention period is left to read
+	if retention := store.Retention(); retention > 0 && from.Before(now.Add(-retention)) {
+		from = now.Add(-retention)
+	}
+
+	var step time.Duration
+	if v := q.Get("step"); v != "" {
+		step, err = time.ParseDuration(v)
+		if err != nil || step < 0 {
+			http.Error(w, "Invalid step", http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := store.Query(id, from, to, step)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      id,
+		"from":    from,
+		"to":      to,
+		"step":    step.String(),
+		"records": records,
+	})
+}
+
+// parseTimeParam accepts RFC3339 timestamps or unix seconds
+func parseTimeParam(v string, def time.Time) (time.Time, error) {
+	if v == "" {
+		return def, nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339 or unix seconds)", v)
+	}
+	return t, nil
+}
+
+// maxLatencyWindow bounds ?window= on the latency endpoint
+const maxLatencyWindow = 30 * 24 * time.Hour
+
+// HandleServiceLatency returns p50/p95/p99 per request phase (dns, connect,
+// tls, ttfb, total) of a service over ?window= (Go duration, default 1h)
+func (h *Handler) HandleServiceLatency(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, exists := h.Registry.Get(id); !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	window := time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxLatencyWindow {
+			http.Error(w, "Invalid window", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	report, err := h.Monitor.Latency(id, window)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, monitor.ErrHistoryDisabled) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
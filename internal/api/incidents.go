@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+)
+
+// defaultIncidentPeriod is the period of /api/incidents without ?since=
+const defaultIncidentPeriod = 30 * 24 * time.Hour
+
+// noteRequest is the body of an acknowledgement or note
+type noteRequest struct {
+	Text string `json:"text"`
+}
+
+// HandleIncidents lists incidents at /api/incidents, newest first, with
+// MTTR/MTTA over the same period. Query params: state (open, resolved),
+// scope (service, category), service, since (RFC3339 or unix seconds,
+// default 30 days ago) and limit.
+func (h *Handler) HandleIncidents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	tracker := h.Monitor.Incidents()
+	if tracker == nil {
+		http.Error(w, "Incidents not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	now := time.Now()
+	since, err := parseTimeParam(q.Get("since"), now.Add(-defaultIncidentPeriod))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f := incident.Filter{
+		State:   q.Get("state"),
+		Scope:   q.Get("scope"),
+		Service: q.Get("service"),
+		Since:   since,
+	}
+	if f.State != "" && f.State != incident.StateOpen && f.State != incident.StateResolved {
+		http.Error(w, "Invalid state", http.StatusBadRequest)
+		return
+	}
+	if f.Scope != "" && f.Scope != incident.ScopeService && f.Scope != incident.ScopeCategory {
+		http.Error(w, "Invalid scope", http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("limit"); v != "" {
+		f.Limit, err = strconv.Atoi(v)
+		if err != nil || f.Limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since":     since,
+		"stats":     tracker.Stats(since, now),
+		"incidents": tracker.List(f, now),
+	})
+}
+
+// HandleIncidentRoutes serves /api/incidents/{id} (GET) and the admin
+// actions /api/incidents/{id}/ack and /api/incidents/{id}/notes (POST,
+// audited). Both take an optional {"text": "..."} body.
+func (h *Handler) HandleIncidentRoutes(w http.ResponseWriter, r *http.Request) {
+	tracker := h.Monitor.Incidents()
+	if tracker == nil {
+		http.Error(w, "Incidents not configured", http.StatusServiceUnavailable)
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/incidents/"), "/")
+	parts := strings.SplitN(rest, "/", 2)
+	id := parts[0]
+	if id == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	sub := ""
+	if len(parts) > 1 {
+		sub = parts[1]
+	}
+
+	if sub == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		inc, err := tracker.Get(id, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(inc)
+		return
+	}
+	if sub != "ack" && sub != "notes" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	var req noteRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	actor := audit.Actor(r)
+	var inc incident.Incident
+	if sub == "ack" {
+		inc, err = tracker.Acknowledge(id, actor, req.Text, time.Now())
+	} else {
+		if strings.TrimSpace(req.Text) == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+		inc, err = tracker.AddNote(id, actor, req.Text, time.Now())
+	}
+	switch {
+	case errors.Is(err, incident.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, incident.ErrAcknowledged):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.recordIncident(r, "incident_"+sub, inc, req.Text)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inc)
+}
+
+func (h *Handler) recordIncident(r *http.Request, action string, inc incident.Incident, text string) {
+	if h.Audit == nil {
+		return
+	}
+	detail := inc.Scope + " " + inc.Target
+	if text != "" {
+		detail += ": " + text
+	}
+	entry := audit.NewEntry(r, action, inc.ID, detail)
+	if err := h.Audit.Record(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
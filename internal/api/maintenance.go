@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/maintenance"
+)
+
+// HandleMaintenance lists (GET) or creates (POST, admin) maintenance windows
+// and silences at /api/maintenance. ?active=true lists only the active ones.
+func (h *Handler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if h.Maintenance == nil {
+		http.Error(w, "Maintenance not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list := h.Maintenance.List(time.Now())
+		if r.URL.Query().Get("active") == "true" {
+			active := list[:0]
+			for _, st := range list {
+				if st.Active {
+					active = append(active, st)
+				}
+			}
+			list = active
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		if !requireAdmin(w, r) {
+			return
+		}
+		var req maintenance.Window
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.CreatedBy = audit.Actor(r)
+		now := time.Now()
+		win, err := h.Maintenance.Add(req, now)
+		if errors.Is(err, maintenance.ErrInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.Monitor.MaintenanceChanged()
+		h.recordMaintenance(r, "maintenance_create", win)
+
+		st, _ := h.Maintenance.Get(win.ID, now)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(st)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleMaintenanceWindow returns (GET) or deletes (DELETE, admin) the window
+// at /api/maintenance/{id}. Deleting an active window ends it immediately.
+func (h *Handler) HandleMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	if h.Maintenance == nil {
+		http.Error(w, "Maintenance not configured", http.StatusServiceUnavailable)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/maintenance/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		st, err := h.Maintenance.Get(id, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(st)
+
+	case http.MethodDelete:
+		if !requireAdmin(w, r) {
+			return
+		}
+		win, err := h.Maintenance.Delete(id)
+		if errors.Is(err, maintenance.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.Monitor.MaintenanceChanged()
+		h.recordMaintenance(r, "maintenance_delete", win)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) recordMaintenance(r *http.Request, action string, win maintenance.Window) {
+	if h.Audit == nil {
+		return
+	}
+	detail := win.Kind
+	if win.Reason != "" {
+		detail += ": " + win.Reason
+	}
+	entry := audit.NewEntry(r, action, win.ID, detail)
+	if err := h.Audit.Record(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/metrics"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// HandleMetrics exposes service and monitor state in the Prometheus text format
+func (h *Handler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	mw := metrics.NewWriter(w)
+
+	services := h.Registry.GetAll()
+
+	type row struct {
+		labels              metrics.Labels
+		id                  string
+		up                  bool
+		status              string
+		responseMs          int64
+		consecutiveFailures int
+		circuitOpen         bool
+		breakerTrips        int
+		updateAvailable     bool
+		timings             *models.PhaseTimings
+		certs               []models.CertStatus
+		dns                 []models.DNSStatus
+	}
+	rows := make([]row, 0, len(services))
+
+	now := time.Now()
+	for _, s := range services {
+		rows = append(rows, row{
+			labels:              metrics.Labels{{"id", s.ID}, {"category", s.Category}},
+			id:                  s.ID,
+			up:                  models.StatusUp(s.Status),
+			status:              s.Status,
+			responseMs:          s.ResponseMs,
+			consecutiveFailures: s.Breaker.Failures,
+			circuitOpen:         !s.Breaker.Closed(),
+			breakerTrips:        s.Breaker.Trips,
+			updateAvailable:     s.UpdateAvailable,
+			timings:             s.Timings,
+			certs:               s.TLS,
+			dns:                 s.DNS,
+		})
+	}
+
+	for _, r := range rows {
+		mw.Gauge("dashboard_service_up", "Whether the service is up (healthy or degraded).", r.labels, metrics.Bool(r.up))
+	}
+	for _, r := range rows {
+		for _, st := range models.Statuses {
+			labels := append(metrics.Labels{}, r.labels...)
+			labels = append(labels, [2]string{"status", st})
+			mw.Gauge("dashboard_service_status", "Current status of the service, one series per status.", labels, metrics.Bool(r.status == st))
+		}
+	}
+	for _, r := range rows {
+		mw.Gauge("dashboard_service_response_ms", "Duration of the last check in milliseconds.", r.labels, float64(r.responseMs))
+	}
+	for _, r := range rows {
+		if hist := h.Monitor.ResponseHistogram(r.id); hist != nil {
+			mw.Histogram("dashboard_service_response_seconds", "Distribution of request latency of passing health checks.", r.labels, hist)
+		}
+	}
+	for _, r := range rows {
+		if t := r.timings; t != nil {
+			phases := []struct {
+				name string
+				ms   int64
+			}{{"dns", t.DNSMs}, {"connect", t.ConnectMs}, {"tls", t.TLSMs}, {"ttfb", t.TTFBMs}, {"total", t.TotalMs}}
+			if t.Reused {
+				phases = phases[3:] // DNS, connect and TLS did not run
+			}
+			for _, p := range phases {
+				labels := append(metrics.Labels{}, r.labels...)
+				labels = append(labels, [2]string{"phase", p.name})
+				mw.Gauge("dashboard_service_phase_seconds", "Phase latency of the last passing health request.", labels, float64(p.ms)/1000)
+			}
+		}
+	}
+	for _, r := range rows {
+		mw.Gauge("dashboard_service_consecutive_failures", "Consecutive failed checks.", r.labels, float64(r.consecutiveFailures))
+	}
+	for _, r := range rows {
+		mw.Gauge("dashboard_service_circuit_open", "Whether the circuit breaker is open or half-open.", r.labels, metrics.Bool(r.circuitOpen))
+	}
+	for _, r := range rows {
+		mw.Gauge("dashboard_service_circuit_trips", "Consecutive breaker openings without a successful probe.", r.labels, float64(r.breakerTrips))
+	}
+	for _, r := range rows {
+		mw.Gauge("dashboard_service_update_available", "Whether a newer image version is published.", r.labels, metrics.Bool(r.updateAvailable))
+	}
+	for _, r := range rows {
+		for _, c := range r.certs {
+			if c.NotAfter.IsZero() {
+				continue
+			}
+			labels := append(metrics.Labels{}, r.labels...)
+			labels = append(labels, [2]string{"host", c.Host})
+			mw.Gauge("dashboard_service_tls_expiry_days", "Days until the public certificate expires.", labels, c.NotAfter.Sub(now).Hours()/24)
+		}
+	}
+	for _, r := range rows {
+		for _, d := range r.dns {
+			labels := append(metrics.Labels{}, r.labels...)
+			labels = append(labels, [2]string{"host", d.Host})
+			mw.Gauge("dashboard_service_dns_ok", "Whether the public hostname resolves to the expected ingress.", labels, metrics.Bool(d.Status == models.DNSOK))
+		}
+	}
+	for _, r := range rows {
+		if report, ok := h.Monitor.Compliance(r.id); ok {
+			mw.Gauge("dashboard_service_compliance_score", "Score of the last compliance scan (0-100).", r.labels, float64(report.TotalScore))
+		}
+	}
+
+	stats := h.Monitor.SchedulerStats()
+	mw.Gauge("dashboard_services", "Number of monitored services.", nil, float64(len(rows)))
+	mw.Header("dashboard_checks_total", "counter", "Completed service checks.")
+	mw.Sample("dashboard_checks_total", nil, float64(stats.Checks))
+	mw.Histogram("dashboard_check_duration_seconds", "Distribution of service check durations, retries included.", nil, h.Monitor.CheckHistogram())
+	mw.Header("dashboard_check_overruns_total", "counter", "Checks that started an interval late or ran longer than their interval.")
+	mw.Sample("dashboard_check_overruns_total", nil, float64(stats.Overruns))
+	mw.Gauge("dashboard_check_max_lag_seconds", "Largest delay between a check being due and starting, over the last window.", nil, stats.MaxLag.D().Seconds())
+	mw.Gauge("dashboard_check_queued", "Checks that are due and waiting for a worker.", nil, float64(stats.Queued))
+	mw.Gauge("dashboard_check_workers", "Size of the check worker pool.", nil, float64(stats.Workers))
+	mw.Gauge("dashboard_check_workers_busy", "Workers currently running a check.", nil, float64(stats.BusyWorkers))
+	mw.Gauge("dashboard_check_worker_utilisation", "Busy worker time divided by pool capacity over the last window.", nil, stats.Utilisation)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+	"github.com/baditaflorin/go_services_dashboard/internal/monitor"
+)
+
+func TestMetricsComplianceFromLastScan(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprint(w, `{"status":"ok","service":"svc"}`)
+	}))
+	defer srv.Close()
+
+	registry := models.NewRegistry()
+	registry.Add(models.Service{ID: "svc", Category: `a "b"`, Port: 8101, HealthURL: srv.URL, Version: "1.0.0"})
+	mon := monitor.NewMonitor(registry, nil)
+	h := NewHandler(registry, mon)
+
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		h.HandleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return rec.Body.String()
+	}
+
+	// A scrape never scans: without a scan there is no gauge
+	if body := scrape(); strings.Contains(body, "dashboard_service_compliance_score") || hits.Load() != 0 {
+		t.Fatalf("scrape scanned compliance (%d requests):\n%s", hits.Load(), body)
+	}
+
+	mon.ScanCompliance()
+	want := `dashboard_service_compliance_score{id="svc",category="a \"b\""} 100`
+	for i := 0; i < 2; i++ {
+		if body := scrape(); !strings.Contains(body, want+"\n") {
+			t.Fatalf("scrape %d: no %s in\n%s", i, want, body)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("got %d compliance requests, want 1", n)
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/network"
+)
+
+// HandleNetwork returns the last Docker network membership audit.
+// Pass ?refresh=true to run a new audit first.
+func (h *Handler) HandleNetwork(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Network == nil {
+		http.Error(w, "Network audit not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	report := h.Network.Last()
+	if report == nil || r.URL.Query().Get("refresh") == "true" {
+		var err error
+		report, err = h.Network.Audit(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"remediation_enabled": h.Network.RemediationEnabled(),
+		"report":              report,
+	})
+}
+
+// HandleNetworkConnect attaches a service's container to the expected network (POST /api/network/connect/{id})
+func (h *Handler) HandleNetworkConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	if h.Network == nil {
+		http.Error(w, "Network audit not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := strings.Trim(r.URL.Path[len("/api/network/connect/"):], "/")
+	if id == "" {
+		http.Error(w, "Missing service ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Network.Connect(r.Context(), id, audit.ClientAddr(r), audit.Claimed(r)); err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, network.ErrRemediationDisabled) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"id":     id,
+		"status": network.StatusAttached,
+	})
+}
+
+// HandleAuditLog returns recent administrative actions (?limit=, default 100)
+func (h *Handler) HandleAuditLog(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if h.Audit == nil {
+		http.Error(w, "Audit log not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	limit := 100
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	entries, err := h.Audit.Recent(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
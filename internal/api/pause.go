@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+	"github.com/baditaflorin/go_services_dashboard/internal/monitor"
+	"github.com/baditaflorin/go_services_dashboard/internal/pause"
+)
+
+// pauseRequest is the optional body of a pause call
+type pauseRequest struct {
+	Reason   string          `json:"reason"`
+	Duration models.Duration `json:"duration"` // 0 = until resumed
+}
+
+// HandlePauses lists the pauses in effect at /api/pauses
+func (h *Handler) HandlePauses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Monitor.Pauses())
+}
+
+// HandleCategoryRoutes dispatches /api/categories/{name}/pause and /resume
+func (h *Handler) HandleCategoryRoutes(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(r.URL.Path[len("/api/categories/"):], "/")
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	name, sub := rest[:i], rest[i+1:]
+	if !h.hasCategory(name) {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+
+	switch sub {
+	case "pause":
+		h.handlePause(w, r, models.PauseCategory, name)
+	case "resume":
+		h.handleResume(w, r, models.PauseCategory, name)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+func (h *Handler) hasCategory(name string) bool {
+	for _, svc := range h.Registry.GetAll() {
+		if svc.Category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePause stops checks of a service or category (admin, audited).
+// The body may give a reason and a duration after which checks resume.
+func (h *Handler) handlePause(w http.ResponseWriter, r *http.Request, scope, target string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req pauseRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Duration < 0 {
+		http.Error(w, "duration must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	p := models.Pause{
+		Scope:    scope,
+		Target:   target,
+		Reason:   req.Reason,
+		PausedBy: audit.Actor(r),
+		PausedAt: now,
+	}
+	if req.Duration > 0 {
+		until := now.Add(req.Duration.D())
+		p.Until = &until
+	}
+	if err := h.Monitor.Pause(p); err != nil {
+		h.pauseError(w, err)
+		return
+	}
+
+	detail := scope
+	if p.Until != nil {
+		detail += " until " + p.Until.Format(time.RFC3339)
+	}
+	if p.Reason != "" {
+		detail += ": " + p.Reason
+	}
+	h.recordPause(r, "pause", target, detail)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// handleResume lifts the pause of a service or category (admin, audited)
+func (h *Handler) handleResume(w http.ResponseWriter, r *http.Request, scope, target string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	p, err := h.Monitor.Resume(scope, target)
+	if err != nil {
+		h.pauseError(w, err)
+		return
+	}
+	h.recordPause(r, "resume", target, scope+" paused by "+p.PausedBy)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"scope":    scope,
+		"target":   target,
+		"previous": p,
+	})
+}
+
+func (h *Handler) pauseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, monitor.ErrPausesDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, pause.ErrNotPaused):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) recordPause(r *http.Request, action, target, detail string) {
+	if h.Audit == nil {
+		return
+	}
+	entry := audit.NewEntry(r, action, target, detail)
+	if err := h.Audit.Record(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
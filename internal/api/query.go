@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// maxPageSize caps ?limit= on list endpoints
+const maxPageSize = 500
+
+// ServiceQuery holds the filters, sort and page of a /api/services request
+type ServiceQuery struct {
+	Category        string
+	Status          string
+	Tag             string
+	TestStatus      string
+	RootCause       string
+	UpdateAvailable *bool
+	Search          string
+	SortKey         string
+	Desc            bool
+	Limit           int
+	After           *models.Service // Decoded cursor: sort value and ID of the last service of the previous page
+}
+
+// sortKey orders services by one field
+type sortKey struct {
+	compare func(a, b *models.Service) int
+	field   func(s *models.Service) any // Pointer to the sorted field, carried in cursors
+}
+
+// sortKeys maps ?sort= values to their ordering
+var sortKeys = map[string]sortKey{
+	"id": {
+		compare: func(a, b *models.Service) int { return strings.Compare(a.ID, b.ID) },
+		field:   func(s *models.Service) any { return &s.ID },
+	},
+	"name": {
+		compare: func(a, b *models.Service) int {
+			return strings.Compare(strings.ToLower(displayName(a)), strings.ToLower(displayName(b)))
+		},
+		field: func(s *models.Service) any {
+			if s.DisplayName != "" {
+				return &s.DisplayName
+			}
+			return &s.Name
+		},
+	},
+	"category": {
+		compare: func(a, b *models.Service) int { return strings.Compare(a.Category, b.Category) },
+		field:   func(s *models.Service) any { return &s.Category },
+	},
+	"status": {
+		compare: func(a, b *models.Service) int { return strings.Compare(a.Status, b.Status) },
+		field:   func(s *models.Service) any { return &s.Status },
+	},
+	"port": {
+		compare: func(a, b *models.Service) int { return compareInt(int64(a.Port), int64(b.Port)) },
+		field:   func(s *models.Service) any { return &s.Port },
+	},
+	"response_ms": {
+		compare: func(a, b *models.Service) int { return compareInt(a.ResponseMs, b.ResponseMs) },
+		field:   func(s *models.Service) any { return &s.ResponseMs },
+	},
+	"last_checked": {
+		compare: func(a, b *models.Service) int { return a.LastChecked.Compare(b.LastChecked) },
+		field:   func(s *models.Service) any { return &s.LastChecked },
+	},
+}
+
+// ParseServiceQuery reads filters from the query string.
+// sort accepts a key optionally prefixed with "-" for descending order;
+// cursor is the opaque value returned in X-Next-Cursor for the same sort.
+func ParseServiceQuery(q url.Values) (ServiceQuery, error) {
+	sq := ServiceQuery{
+		Category:   q.Get("category"),
+		Status:     q.Get("status"),
+		Tag:        q.Get("tag"),
+		TestStatus: q.Get("test_status"),
+		RootCause:  q.Get("root_cause"),
+		Search:     strings.ToLower(strings.TrimSpace(q.Get("q"))),
+		SortKey:    "id",
+	}
+
+	if v := q.Get("update_available"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return sq, fmt.Errorf("invalid update_available %q", v)
+		}
+		sq.UpdateAvailable = &b
+	}
+
+	if v := q.Get("sort"); v != "" {
+		if strings.HasPrefix(v, "-") {
+			sq.Desc = true
+			v = v[1:]
+		}
+		if _, ok := sortKeys[v]; !ok {
+			return sq, fmt.Errorf("invalid sort key %q", v)
+		}
+		sq.SortKey = v
+	}
+	if q.Get("order") == "desc" {
+		sq.Desc = true
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return sq, fmt.Errorf("invalid limit %q", v)
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		sq.Limit = n
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		after, err := sq.decodeCursor(v)
+		if err != nil {
+			return sq, err
+		}
+		sq.After = after
+	}
+	return sq, nil
+}
+
+// Matches reports whether a service passes every filter
+func (sq ServiceQuery) Matches(s *models.Service) bool {
+	if sq.Category != "" && s.Category != sq.Category {
+		return false
+	}
+	if sq.Status != "" && s.Status != sq.Status {
+		return false
+	}
+	if sq.TestStatus != "" && s.TestStatus != sq.TestStatus {
+		return false
+	}
+	if sq.RootCause != "" && s.RootCause != sq.RootCause {
+		return false
+	}
+	if sq.UpdateAvailable != nil && s.UpdateAvailable != *sq.UpdateAvailable {
+		return false
+	}
+	if sq.Tag != "" && !hasTag(s, sq.Tag) {
+		return false
+	}
+	if sq.Search != "" {
+		haystack := strings.ToLower(strings.Join([]string{s.ID, s.Name, s.DisplayName, s.Description, strings.Join(s.Tags, " ")}, " "))
+		if !strings.Contains(haystack, sq.Search) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply filters, sorts and pages the list.
+// It returns the page, the total number of matches and the next cursor ("" on the last page).
+func (sq ServiceQuery) Apply(list []models.Service) ([]models.Service, int, string) {
+	matched := make([]models.Service, 0, len(list))
+	for i := range list {
+		if sq.Matches(&list[i]) {
+			matched = append(matched, list[i])
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return sq.compare(&matched[i], &matched[j]) < 0
+	})
+
+	total := len(matched)
+	if sq.Limit == 0 {
+		return matched, total, ""
+	}
+
+	// Keyset paging: the page starts after the cursor's position, so services
+	// added or removed meanwhile neither repeat nor skip entries
+	start := 0
+	if sq.After != nil {
+		start = sort.Search(total, func(i int) bool { return sq.compare(&matched[i], sq.After) > 0 })
+	}
+	end := start + sq.Limit
+	next := ""
+	if end < total {
+		next = sq.encodeCursor(&matched[end-1])
+	} else {
+		end = total
+	}
+	return matched[start:end], total, next
+}
+
+// compare orders services by the sort key and direction, then by ID
+func (sq ServiceQuery) compare(a, b *models.Service) int {
+	c := sortKeys[sq.SortKey].compare(a, b)
+	if sq.Desc {
+		c = -c
+	}
+	if c == 0 {
+		// Tie-break on ID so pages are stable
+		c = strings.Compare(a.ID, b.ID)
+	}
+	return c
+}
+
+// cursor is the position after which the next page starts
+type cursor struct {
+	Sort  string          `json:"s"` // Sort the cursor was made for, "-" prefixed when descending
+	ID    string          `json:"id"`
+	Value json.RawMessage `json:"v"`
+}
+
+func (sq ServiceQuery) sortName() string {
+	if sq.Desc {
+		return "-" + sq.SortKey
+	}
+	return sq.SortKey
+}
+
+func (sq ServiceQuery) encodeCursor(last *models.Service) string {
+	value, _ := json.Marshal(sortKeys[sq.SortKey].field(last))
+	raw, _ := json.Marshal(cursor{Sort: sq.sortName(), ID: last.ID, Value: value})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor returns a service carrying the cursor's ID and sort value
+func (sq ServiceQuery) decodeCursor(c string) (*models.Service, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var cur cursor
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cur.Sort != sq.sortName() {
+		return nil, fmt.Errorf("cursor was made for sort %q", cur.Sort)
+	}
+	after := &models.Service{ID: cur.ID}
+	if err := json.Unmarshal(cur.Value, sortKeys[sq.SortKey].field(after)); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return after, nil
+}
+
+func displayName(s *models.Service) string {
+	if s.DisplayName != "" {
+		return s.DisplayName
+	}
+	return s.Name
+}
+
+func hasTag(s *models.Service, tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// pageIDs fetches every page of q over list and returns the IDs in order
+func pageIDs(t *testing.T, values url.Values, list func() []models.Service) []string {
+	t.Helper()
+	var ids []string
+	for i := 0; i < 20; i++ {
+		q, err := ParseServiceQuery(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, _, next := q.Apply(list())
+		for _, s := range page {
+			ids = append(ids, s.ID)
+		}
+		if next == "" {
+			return ids
+		}
+		values.Set("cursor", next)
+	}
+	t.Fatal("paging did not end")
+	return nil
+}
+
+func TestKeysetPaging(t *testing.T) {
+	services := []models.Service{
+		{ID: "a", Port: 8103},
+		{ID: "b", Port: 8101},
+		{ID: "c", Port: 8102},
+		{ID: "d", Port: 8101},
+		{ID: "e", Port: 8104},
+	}
+	all := func() []models.Service { return services }
+
+	got := pageIDs(t, url.Values{"sort": {"port"}, "limit": {"2"}}, all)
+	if strings.Join(got, ",") != "b,d,c,a,e" {
+		t.Errorf("got %v", got)
+	}
+	got = pageIDs(t, url.Values{"sort": {"-port"}, "limit": {"2"}}, all)
+	if strings.Join(got, ",") != "e,a,c,b,d" {
+		t.Errorf("got %v descending", got)
+	}
+}
+
+func TestKeysetPagingSurvivesChanges(t *testing.T) {
+	services := []models.Service{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	q, _ := ParseServiceQuery(url.Values{"limit": {"2"}})
+	page, _, next := q.Apply(services)
+	if len(page) != 2 || page[1].ID != "b" {
+		t.Fatalf("got %+v", page)
+	}
+
+	// Removing a service already shown must not skip the next one
+	services = []models.Service{{ID: "b"}, {ID: "c"}, {ID: "d"}}
+	q, err := ParseServiceQuery(url.Values{"limit": {"2"}, "cursor": {next}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, total, _ := q.Apply(services)
+	if len(page) != 2 || page[0].ID != "c" || page[1].ID != "d" || total != 3 {
+		t.Errorf("got %+v (total %d), want c and d", page, total)
+	}
+}
+
+func TestCursorMustMatchSort(t *testing.T) {
+	services := []models.Service{{ID: "a", DisplayName: "Zed"}, {ID: "b", Name: "alpha"}, {ID: "c", Name: "beta"}}
+	q, _ := ParseServiceQuery(url.Values{"sort": {"name"}, "limit": {"1"}})
+	_, _, next := q.Apply(services)
+
+	if _, err := ParseServiceQuery(url.Values{"sort": {"port"}, "cursor": {next}}); err == nil {
+		t.Error("expected an error for a cursor of another sort")
+	}
+	if _, err := ParseServiceQuery(url.Values{"cursor": {"bm90LWpzb24"}}); err == nil {
+		t.Error("expected an error for a malformed cursor")
+	}
+
+	got := pageIDs(t, url.Values{"sort": {"name"}, "limit": {"1"}}, func() []models.Service { return services })
+	if strings.Join(got, ",") != "b,c,a" {
+		t.Errorf("got %v", got)
+	}
+}
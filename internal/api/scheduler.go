@@ -0,0 +1,20 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleScheduler returns the check scheduler statistics and the schedule of every service
+func (h *Handler) HandleScheduler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"stats":    h.Monitor.SchedulerStats(),
+		"services": h.Monitor.Schedules(),
+	})
+}
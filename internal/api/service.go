@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/compliance"
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// detailHistoryLimit caps the recent checks embedded in the detail view
+const detailHistoryLimit = 50
+
+// detailIncidentLimit caps the recent incidents embedded in the detail view
+const detailIncidentLimit = 10
+
+// ActiveTest is the last result of the active link test
+type ActiveTest struct {
+	Status   string    `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	TestedAt time.Time `json:"tested_at,omitempty"`
+}
+
+// VersionInfo summarises the running and published image versions
+type VersionInfo struct {
+	Current         string `json:"current"`
+	Latest          string `json:"latest,omitempty"`
+	UpdateAvailable bool   `json:"update_available"`
+}
+
+// ServiceDetail is the full view of one service
+type ServiceDetail struct {
+	Service    models.Service               `json:"service"`
+	History    []history.Record             `json:"history"`
+	Compliance *compliance.ComplianceReport `json:"compliance"`
+	ActiveTest ActiveTest                   `json:"active_test"`
+	Version    VersionInfo                  `json:"version"`
+	Diagnosis  *models.Diagnosis            `json:"diagnosis"`
+	Incidents  []incident.Incident          `json:"incidents"` // Most recent first, service and category incidents
+}
+
+// HandleGetService returns the detail view of /api/services/{id}
+func (h *Handler) HandleGetService(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshot, exists := h.Registry.Get(id)
+	if !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	detail := ServiceDetail{
+		Service: snapshot,
+		History: []history.Record{},
+		ActiveTest: ActiveTest{
+			Status:   snapshot.TestStatus,
+			Error:    snapshot.TestError,
+			TestedAt: snapshot.LastTested,
+		},
+		Version: VersionInfo{
+			Current:         snapshot.Version,
+			Latest:          snapshot.LatestVersion,
+			UpdateAvailable: snapshot.UpdateAvailable,
+		},
+		Diagnosis: snapshot.Diagnosis,
+		Incidents: []incident.Incident{},
+	}
+
+	if store := h.Monitor.History(); store != nil {
+		now := time.Now()
+		records, err := store.Query(id, now.Add(-24*time.Hour), now, 0)
+		if err == nil {
+			if len(records) > detailHistoryLimit {
+				records = records[len(records)-detailHistoryLimit:]
+			}
+			detail.History = records
+		}
+	}
+
+	if tracker := h.Monitor.Incidents(); tracker != nil {
+		detail.Incidents = tracker.List(incident.Filter{Service: id, Limit: detailIncidentLimit}, time.Now())
+	}
+
+	if report, ok := h.Monitor.Compliance(id); ok {
+		detail.Compliance = &report
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleSLA returns rolling uptime and error budgets per service and category.
+// Pass ?category= to restrict the service list to one category.
+func (h *Handler) HandleSLA(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := h.Monitor.SLAReport()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if category := r.URL.Query().Get("category"); category != "" {
+		filtered := *report
+		filtered.Services = filtered.Services[:0:0]
+		for _, s := range report.Services {
+			if s.Category == category {
+				filtered.Services = append(filtered.Services, s)
+			}
+		}
+		filtered.Categories = filtered.Categories[:0:0]
+		for _, c := range report.Categories {
+			if c.Category == category {
+				filtered.Categories = append(filtered.Categories, c)
+			}
+		}
+		report = &filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/statuspage"
+)
+
+// HandleStatusPage serves the public, read-only status page: /status (HTML),
+// /status/summary.json, and the incident feeds /status/incidents.json,
+// /status/incidents.rss and /status/incidents.atom. Nothing internal (IDs,
+// ports, container names, URLs, errors or notes) is part of it.
+func (h *Handler) HandleStatusPage(w http.ResponseWriter, r *http.Request) {
+	if h.StatusPage == nil {
+		http.Error(w, "Status page not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	summary, err := h.StatusPage.Summary()
+	if err != nil {
+		log.Printf("Status page failed: %v", err)
+		http.Error(w, "Status unavailable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=60")
+
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/status":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := h.StatusPage.Render(w, summary); err != nil {
+			log.Printf("Status page render failed: %v", err)
+		}
+	case "/status/summary.json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summary)
+	case "/status/incidents.json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summary.Incidents)
+	case "/status/incidents.rss":
+		h.writeFeed(w, "application/rss+xml; charset=utf-8", h.StatusPage.RSS, summary)
+	case "/status/incidents.atom":
+		h.writeFeed(w, "application/atom+xml; charset=utf-8", h.StatusPage.Atom, summary)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+func (h *Handler) writeFeed(w http.ResponseWriter, contentType string, render func(*statuspage.Summary) ([]byte, error), summary *statuspage.Summary) {
+	data, err := render(summary)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
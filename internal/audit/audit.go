@@ -0,0 +1,195 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one administrative action
+type Entry struct {
+	Time         time.Time `json:"time"`
+	Actor        string    `json:"actor"`                   // Client address of the request, see ClientAddr
+	ClaimedActor string    `json:"claimed_actor,omitempty"` // X-Actor header as sent by the client, not verified
+	Action       string    `json:"action"`
+	Target       string    `json:"target"`
+	Detail       string    `json:"detail,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// NewEntry starts the entry of an action requested by r. The admin token is
+// shared, so the only identity is the client address; the X-Actor name is
+// kept apart as claimed.
+func NewEntry(r *http.Request, action, target, detail string) Entry {
+	return Entry{
+		Actor:        ClientAddr(r),
+		ClaimedActor: Claimed(r),
+		Action:       action,
+		Target:       target,
+		Detail:       detail,
+	}
+}
+
+// Log is an append-only JSON lines audit log
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLog opens (or creates) the audit log at path
+func NewLog(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+	return &Log{path: path}, nil
+}
+
+// Record appends an entry
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Recent returns up to n of the newest entries, newest last
+func (l *Log) Recent(n int) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, scanner.Err()
+}
+
+// Claimed returns the name the client sent in X-Actor, which nothing verifies
+func Claimed(r *http.Request) string {
+	return r.Header.Get("X-Actor")
+}
+
+// Actor describes who made a request for display: the client address,
+// preceded by the X-Actor name marked as claimed when one is sent
+func Actor(r *http.Request) string {
+	if a := Claimed(r); a != "" {
+		return fmt.Sprintf("%s (claimed) from %s", a, ClientAddr(r))
+	}
+	return ClientAddr(r)
+}
+
+var (
+	proxiesMu      sync.RWMutex
+	trustedProxies []*net.IPNet
+)
+
+// SetTrustedProxies sets the reverse proxies (IPs or CIDRs) whose
+// X-Forwarded-For and X-Real-IP headers are believed. None are trusted by default.
+func SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return fmt.Errorf("invalid proxy address %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("invalid proxy address %q: %w", p, err)
+		}
+		nets = append(nets, n)
+	}
+	proxiesMu.Lock()
+	trustedProxies = nets
+	proxiesMu.Unlock()
+	return nil
+}
+
+// trusted reports whether addr (an IP, with or without port) is a trusted proxy
+func trusted(addr string) bool {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	proxiesMu.RLock()
+	defer proxiesMu.RUnlock()
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientAddr returns the address of the client that made r. Requests from a
+// trusted proxy are attributed to the nearest untrusted hop of
+// X-Forwarded-For, or to X-Real-IP; anything else to r.RemoteAddr, since
+// headers from an untrusted peer can be forged.
+func ClientAddr(r *http.Request) string {
+	if !trusted(r.RemoteAddr) {
+		return r.RemoteAddr
+	}
+	if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+		hops := strings.Split(strings.Join(fwd, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && !trusted(hop) {
+				return hop
+			}
+		}
+		// Every hop is a proxy: the left-most is as close to the client as it gets
+		if first := strings.TrimSpace(hops[0]); first != "" {
+			return first
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return r.RemoteAddr
+}
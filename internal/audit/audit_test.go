@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestEntryKeepsClaimedActorApart(t *testing.T) {
+	l, err := NewLog(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("POST", "/api/services/svc/pause", nil)
+	r.RemoteAddr = "10.0.0.7:51234"
+	r.Header.Set("X-Actor", "alice")
+
+	if err := l.Record(NewEntry(r, "service_pause", "svc", "")); err != nil {
+		t.Fatal(err)
+	}
+	anon := httptest.NewRequest("POST", "/api/services/svc/resume", nil)
+	anon.RemoteAddr = "10.0.0.8:40000"
+	if err := l.Record(NewEntry(anon, "service_resume", "svc", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := l.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if e := entries[0]; e.Actor != "10.0.0.7:51234" || e.ClaimedActor != "alice" {
+		t.Errorf("got actor %q, claimed %q", e.Actor, e.ClaimedActor)
+	}
+	if e := entries[1]; e.Actor != "10.0.0.8:40000" || e.ClaimedActor != "" {
+		t.Errorf("got actor %q, claimed %q", e.Actor, e.ClaimedActor)
+	}
+
+	if got := Actor(r); got != "alice (claimed) from 10.0.0.7:51234" {
+		t.Errorf("got %q", got)
+	}
+	if got := Actor(anon); got != "10.0.0.8:40000" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestClientAddr(t *testing.T) {
+	if err := SetTrustedProxies([]string{"172.18.0.5", "10.1.0.0/16"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+
+	tests := []struct {
+		name   string
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"direct", "10.0.0.7:51234", nil, "10.0.0.7:51234"},
+		{"untrusted peer cannot forge", "10.0.0.7:51234", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "10.0.0.7:51234"},
+		{"proxy", "172.18.0.5:40000", map[string]string{"X-Forwarded-For": "203.0.113.9"}, "203.0.113.9"},
+		{"spoofed hop ignored", "172.18.0.5:40000", map[string]string{"X-Forwarded-For": "6.6.6.6, 203.0.113.9"}, "203.0.113.9"},
+		{"proxy chain", "172.18.0.5:40000", map[string]string{"X-Forwarded-For": "203.0.113.9, 10.1.2.3"}, "203.0.113.9"},
+		{"only proxies", "172.18.0.5:40000", map[string]string{"X-Forwarded-For": "10.1.2.3"}, "10.1.2.3"},
+		{"real ip", "172.18.0.5:40000", map[string]string{"X-Real-IP": "203.0.113.9"}, "203.0.113.9"},
+		{"proxy without headers", "172.18.0.5:40000", nil, "172.18.0.5:40000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/network/connect/svc", nil)
+			r.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			if got := ClientAddr(r); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("POST", "/api/services/svc/pause", nil)
+	r.RemoteAddr = "172.18.0.5:40000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.9")
+	r.Header.Set("X-Actor", "alice")
+	if e := NewEntry(r, "service_pause", "svc", ""); e.Actor != "203.0.113.9" {
+		t.Errorf("got actor %q", e.Actor)
+	}
+	if got := Actor(r); got != "alice (claimed) from 203.0.113.9" {
+		t.Errorf("got %q", got)
+	}
+
+	if err := SetTrustedProxies([]string{"nginx"}); err == nil {
+		t.Error("expected an error for a host name")
+	}
+}
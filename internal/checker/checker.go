@@ -6,7 +6,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/baditaflorin/go_services_dashboard/internal/models"
@@ -19,21 +18,34 @@ type CheckServiceResult struct {
 	ExampleStatus string
 	LastError     string
 	Version       string
-	ResponseMs    int64
+	ResponseMs    int64                // Wall time of the whole check, including retries across hosts
+	Timings       *models.PhaseTimings // Phase breakdown of the request that passed the health check
+	Diagnosis     models.Diagnosis     // Every request made and the classified root cause
+	DockerName    string               // Internal host that answered the health check, "" if none
 }
 
-// CheckService performs the health check logic
+// CheckService performs the health check logic described by the service's check spec.
+// svc must be a copy owned by the caller: its DockerName is updated with the
+// host that answered, which is also reported in the result.
 func CheckService(client *http.Client, svc *models.Service) CheckServiceResult {
 	start := time.Now()
+	spec := ResolveCheckSpec(svc)
 
 	healthOK := false
 	exampleOK := false
 	version := ""
+	var timings *models.PhaseTimings
 	healthError := ""
 	exampleError := ""
+	degradedReason := "" // Why a passing health check is degraded
+	publicExampleOK := true
+	dockerName := ""
+	internalFailed := true // No acceptable internal answer; only then is HealthURL tried
 
-	// STEP 1: Test Internal /health endpoint
-	resp, resolveURL, err := TryInternalRequest(client, svc, "/health")
+	// STEP 1: Test Internal health endpoint
+	internal, err := tryInternal(client, svc, models.AttemptInternalHealth, spec.Method, spec.Path, spec.Headers, spec.Body, spec.ExpectedStatus)
+	resp, resolveURL := internal.Resp, internal.URL
+	attempts := internal.Attempts
 
 	// DEBUG 8155
 	if svc.Port == 8155 {
@@ -44,100 +56,107 @@ func CheckService(client *http.Client, svc *models.Service) CheckServiceResult {
 		}
 	}
 
-	if err == nil && resp != nil && resp.StatusCode == 200 {
-		var healthResp struct {
-			Status  string `json:"status"`
-			Version string `json:"version"`
-		}
-		if decodeErr := json.NewDecoder(resp.Body).Decode(&healthResp); decodeErr == nil {
-			if healthResp.Status == "healthy" || healthResp.Status == "ok" {
-				healthOK = true
-				version = healthResp.Version
-			} else {
-				healthError = fmt.Sprintf("Internal health status: %s", healthResp.Status)
-				if svc.Port == 8155 {
-					log.Printf("[DEBUG-8155] Status rejected: %s\n", healthResp.Status)
-				}
-			}
-		} else {
-			// If strictly JSON is required, this should fail. But historically we allowed 200 OK.
-			// Let's assume healthy if 200 OK but verify logs.
-			healthOK = true
-		}
+	if err == nil && resp != nil {
+		ok, v, reason := evaluateHealth(resp, spec, internal.ElapsedMs)
 		resp.Body.Close()
 
 		// Update discovered connection details
 		u, _ := url.Parse(resolveURL)
 		if u != nil {
-			svc.DockerName = u.Hostname()
+			dockerName = u.Hostname()
+			svc.DockerName = dockerName
 		}
-	} else {
-		if err != nil {
-			healthError = fmt.Sprintf("Internal health: %v", err)
-		} else if resp != nil {
-			healthError = fmt.Sprintf("Internal health: HTTP %d", resp.StatusCode)
-			resp.Body.Close()
+
+		winner := &attempts[len(attempts)-1]
+		// The default spec keeps the historical fallback on any rejected answer;
+		// a declared spec only falls back when no expected status came back
+		internalFailed = !statusExpected(resp.StatusCode, spec.ExpectedStatus) || (svc.Check == nil && !ok)
+		if ok {
+			healthOK = true
+			version = v
+			degradedReason = reason
+			winner.Error, winner.ErrorClass = "", ""
+			t := winner.Timings()
+			timings = &t
+		} else {
+			rejectAttempt(winner, reason)
+			healthError = fmt.Sprintf("Internal health: %s", reason)
+			if svc.Port == 8155 {
+				log.Printf("[DEBUG-8155] Status rejected: %s\n", reason)
+			}
 		}
+	} else if err != nil {
+		healthError = fmt.Sprintf("Internal health: %v%s", err, networkHint(svc))
+	}
 
-		// Fallback to public HealthURL
-		if svc.HealthURL != "" {
-			resp, err := client.Get(svc.HealthURL)
-			if err == nil && resp.StatusCode == 200 {
-				// Check Public Status too
-				var healthResp struct {
-					Status  string `json:"status"`
-					Version string `json:"version"`
-				}
-				if decodeErr := json.NewDecoder(resp.Body).Decode(&healthResp); decodeErr == nil {
-					if healthResp.Status == "healthy" || healthResp.Status == "ok" {
-						healthOK = true
-						version = healthResp.Version
-					}
-				} else {
-					healthOK = true
-				}
+	// Fallback to public HealthURL
+	if internalFailed && svc.HealthURL != "" && *spec.PublicFallback {
+		req, err := newRequest(spec.Method, svc.HealthURL, spec.Headers, spec.Body)
+		if err == nil {
+			var resp *http.Response
+			var attempt models.Attempt
+			resp, attempt, err = doTraced(client, req, models.AttemptPublicHealth)
+			if err == nil {
+				ok, v, reason := evaluateHealth(resp, spec, attempt.ElapsedMs)
 				resp.Body.Close()
-			} else {
-				if err != nil {
-					healthError = fmt.Sprintf("%s | Public health: %v", healthError, err)
-				} else if resp != nil {
-					healthError = fmt.Sprintf("%s | Public health: HTTP %d", healthError, resp.StatusCode)
-					resp.Body.Close()
+				if ok {
+					healthOK = true
+					version = v
+					degradedReason = reason
+					attempt.Error, attempt.ErrorClass = "", ""
+					t := attempt.Timings()
+					timings = &t
+				} else {
+					rejectAttempt(&attempt, reason)
+					healthError = fmt.Sprintf("%s | Public health: %s", healthError, reason)
 				}
 			}
+			attempts = append(attempts, attempt)
+		}
+		if err != nil {
+			healthError = fmt.Sprintf("%s | Public health: %v", healthError, err)
 		}
 	}
 
 	// STEP 2: Test ExampleURL (actual service functionality)
-	if svc.ExampleURL != "" {
-		// 1. Try Public URL First (End-to-End Check)
-		resp, err := client.Get(svc.ExampleURL)
+	if svc.ExampleURL != "" && spec.Example != nil {
+		ex := *spec.Example
 
+		// 1. Try Public URL First (End-to-End Check)
 		publicOK := false
+		req, err := newRequest(ex.Method, svc.ExampleURL, ex.Headers, ex.Body)
 		if err == nil {
-			ct := resp.Header.Get("Content-Type")
-			if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-				if strings.Contains(ct, "text/html") {
-					exampleError = fmt.Sprintf("Public: Unexpected HTML (HTTP %d)", resp.StatusCode)
-				} else {
+			var resp *http.Response
+			var attempt models.Attempt
+			resp, attempt, err = doTraced(client, req, models.AttemptPublicExample)
+			if err == nil {
+				ok, reason := evaluateExample(resp, ex, attempt.ElapsedMs)
+				resp.Body.Close()
+				if ok {
 					publicOK = true
 					exampleOK = true
+					attempt.Error, attempt.ErrorClass = "", ""
+				} else {
+					rejectAttempt(&attempt, reason)
+					exampleError = fmt.Sprintf("Public: %s", reason)
 				}
-			} else {
-				exampleError = fmt.Sprintf("Public HTTP %d: %s", resp.StatusCode, resp.Status)
 			}
-			resp.Body.Close()
-		} else {
+			attempts = append(attempts, attempt)
+		}
+		if err != nil {
 			exampleError = fmt.Sprintf("Public Connection: %v", err)
 		}
 
 		// 2. If Public failed, Try Internal (Diagnosis)
+		publicExampleOK = publicOK
 		if !publicOK {
 			path := GetPathFromURL(svc.ExampleURL)
-			resp, _, err := TryInternalRequest(client, svc, path)
+			exInternal, err := tryInternal(client, svc, models.AttemptInternalExample, ex.Method, path, ex.Headers, ex.Body, ex.ExpectedStatus)
+			attempts = append(attempts, exInternal.Attempts...)
+			resp := exInternal.Resp
 			if err == nil && resp != nil {
 				// We have internal connectivity
-				if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+				if statusExpected(resp.StatusCode, ex.ExpectedStatus) || (len(ex.ExpectedStatus) == 0 && resp.StatusCode >= 200 && resp.StatusCode < 400) {
 					// Internal is fine, but Public failed -> Mark as Healthy (Internal)
 					exampleError = fmt.Sprintf("%s | Internal OK (HTTP %d)", exampleError, resp.StatusCode)
 					exampleOK = true
@@ -146,7 +165,7 @@ func CheckService(client *http.Client, svc *models.Service) CheckServiceResult {
 				}
 				resp.Body.Close()
 			} else {
-				exampleError = fmt.Sprintf("%s | Internal unreachable", exampleError)
+				exampleError = fmt.Sprintf("%s | Internal unreachable%s", exampleError, networkHint(svc))
 			}
 		}
 	} else {
@@ -156,20 +175,25 @@ func CheckService(client *http.Client, svc *models.Service) CheckServiceResult {
 	}
 
 	// STEP 3: Compute final status
-	// PRIMARY: healthOK determines if service is healthy
-	// SECONDARY: exampleOK is informational (for debugging network/proxy issues)
+	// PRIMARY: healthOK decides between up and unhealthy
+	// SECONDARY: a failing example URL or a slow health response only degrades a passing service
 	var status string
 	var lastError string
 	if healthOK {
-		// Health endpoint passed - service is healthy
-		// Example URL failures are informational only (network/proxy issues)
-		status = "healthy"
-		if !exampleOK {
-			lastError = fmt.Sprintf("Healthy (Example URL issue: %s)", exampleError)
+		if degradedReason == "" && spec.DegradedMs > 0 && timings != nil && timings.TotalMs > spec.DegradedMs {
+			degradedReason = fmt.Sprintf("slow health response (%dms > %dms)", timings.TotalMs, spec.DegradedMs)
+		}
+		if degradedReason == "" && !publicExampleOK {
+			degradedReason = fmt.Sprintf("Example URL issue: %s", exampleError)
+		}
+		status = models.StatusHealthy
+		if degradedReason != "" {
+			status = models.StatusDegraded
+			lastError = fmt.Sprintf("Degraded (%s)", degradedReason)
 		}
 	} else {
 		// Health endpoint failed - service is truly unhealthy
-		status = "unhealthy"
+		status = models.StatusUnhealthy
 		lastError = healthError
 	}
 
@@ -182,9 +206,20 @@ func CheckService(client *http.Client, svc *models.Service) CheckServiceResult {
 		LastError:     lastError,
 		Version:       version,
 		ResponseMs:    elapsed,
+		Timings:       timings,
+		Diagnosis:     Diagnose(svc, attempts, healthOK),
+		DockerName:    dockerName,
 	}
 }
 
+// networkHint explains internal failures caused by a container missing from the Docker network
+func networkHint(svc *models.Service) string {
+	if svc.NetworkStatus == "missing" {
+		return " (container not attached to the dashboard's Docker network)"
+	}
+	return ""
+}
+
 func parseVersion(resp *http.Response) string {
 	var healthResp struct {
 		Version string `json:"version"`
@@ -0,0 +1,156 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestResolveCheckSpecStatusField(t *testing.T) {
+	tests := []struct {
+		name      string
+		check     *models.CheckSpec
+		wantField string
+		wantValue string
+	}{
+		{name: "default", wantField: "status", wantValue: "healthy"},
+		{name: "custom field", check: &models.CheckSpec{StatusField: "data.state"}, wantField: "data.state", wantValue: "healthy"},
+		{name: "values only", check: &models.CheckSpec{StatusValues: []string{"up"}}, wantField: "status", wantValue: "up"},
+		{name: "skipped", check: &models.CheckSpec{SkipStatus: true, StatusField: "data.state"}, wantField: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := ResolveCheckSpec(&models.Service{Check: tt.check})
+			if spec.StatusField != tt.wantField {
+				t.Errorf("got status field %q, want %q", spec.StatusField, tt.wantField)
+			}
+			if tt.wantValue != "" && spec.StatusValues[0] != tt.wantValue {
+				t.Errorf("got status values %v", spec.StatusValues)
+			}
+		})
+	}
+}
+
+// stubService serves the internal health endpoint and counts public health requests
+type stubService struct {
+	internal *httptest.Server
+	public   *httptest.Server
+	hits     atomic.Int32
+}
+
+func newStubService(t *testing.T, status int, body string) *stubService {
+	t.Helper()
+	s := &stubService{}
+	s.internal = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	s.public = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	t.Cleanup(s.internal.Close)
+	t.Cleanup(s.public.Close)
+	return s
+}
+
+func (s *stubService) service(check *models.CheckSpec) *models.Service {
+	u, _ := url.Parse(s.internal.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return &models.Service{
+		ID:         "svc",
+		DockerName: u.Hostname(),
+		Port:       port,
+		HealthURL:  s.public.URL + "/health",
+		Check:      check,
+	}
+}
+
+// testClient only dials loopback, so host.docker.internal and container names fail fast
+func testClient() *http.Client {
+	dialer := &net.Dialer{Timeout: time.Second}
+	return &http.Client{Timeout: 2 * time.Second, Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if host, _, _ := net.SplitHostPort(addr); host != "127.0.0.1" {
+				return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestCheckServicePublicFallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		check        *models.CheckSpec
+		wantStatus   string
+		wantFallback bool
+	}{
+		{name: "internal healthy", status: 200, body: `{"status":"ok"}`, wantStatus: models.StatusHealthy},
+		{name: "rejected answer falls back by default", status: 200, body: `{"status":"broken"}`, wantStatus: models.StatusHealthy, wantFallback: true},
+		{name: "declared spec does not retry a rejected answer", status: 200, body: `{"status":"broken"}`,
+			check: &models.CheckSpec{StatusValues: []string{"ok"}}, wantStatus: models.StatusUnhealthy},
+		{name: "unexpected status falls back", status: 503, body: `{}`, wantStatus: models.StatusHealthy, wantFallback: true},
+		{name: "expected 5xx is an answer", status: 503, body: `{"status":"ok"}`,
+			check: &models.CheckSpec{ExpectedStatus: []int{200, 503}}, wantStatus: models.StatusHealthy},
+		{name: "skipped status field", status: 200, body: `{"status":"broken"}`,
+			check: &models.CheckSpec{SkipStatus: true}, wantStatus: models.StatusHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubService(t, tt.status, tt.body)
+			res := CheckService(testClient(), stub.service(tt.check))
+			if res.Status != tt.wantStatus {
+				t.Errorf("got %s (%s), want %s", res.Status, res.LastError, tt.wantStatus)
+			}
+			if fellBack := stub.hits.Load() > 0; fellBack != tt.wantFallback {
+				t.Errorf("got public fallback %v, want %v", fellBack, tt.wantFallback)
+			}
+			if !tt.wantFallback && res.DockerName != "127.0.0.1" {
+				t.Errorf("got docker name %q", res.DockerName)
+			}
+		})
+	}
+}
+
+func TestCheckServiceRejectionReason(t *testing.T) {
+	stub := newStubService(t, 200, `{"status":"broken"}`)
+	res := CheckService(testClient(), stub.service(&models.CheckSpec{StatusValues: []string{"ok"}}))
+	if !strings.Contains(res.LastError, "health status: broken") || strings.Contains(res.LastError, "Public health") {
+		t.Errorf("got %q", res.LastError)
+	}
+}
+
+func TestCheckServiceDegradedMsOptIn(t *testing.T) {
+	slow := func(t *testing.T) *models.Service {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		t.Cleanup(srv.Close)
+		u, _ := url.Parse(srv.URL)
+		port, _ := strconv.Atoi(u.Port())
+		return &models.Service{ID: "svc", DockerName: u.Hostname(), Port: port}
+	}
+
+	if res := CheckService(testClient(), slow(t)); res.Status != models.StatusHealthy {
+		t.Errorf("got %s (%s) without degraded_ms, want healthy", res.Status, res.LastError)
+	}
+	svc := slow(t)
+	svc.Check = &models.CheckSpec{DegradedMs: 10}
+	if res := CheckService(testClient(), svc); res.Status != models.StatusDegraded || !strings.Contains(res.LastError, "slow health response") {
+		t.Errorf("got %s (%s) with degraded_ms, want degraded", res.Status, res.LastError)
+	}
+}
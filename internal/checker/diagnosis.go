@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Diagnose classifies the root cause of a check from the attempts it made.
+// Internal evidence wins over public evidence: a public 502 is only blamed on
+// the proxy when the container itself answered.
+func Diagnose(svc *models.Service, attempts []models.Attempt, healthOK bool) models.Diagnosis {
+	if attempts == nil {
+		attempts = []models.Attempt{}
+	}
+	cause, summary := classify(svc, attempts, healthOK)
+	return models.Diagnosis{RootCause: cause, Summary: summary, Attempts: attempts}
+}
+
+func classify(svc *models.Service, attempts []models.Attempt, healthOK bool) (string, string) {
+	internalHealth := attemptsOfKind(attempts, models.AttemptInternalHealth)
+	publicHealth := attemptsOfKind(attempts, models.AttemptPublicHealth)
+	publicExample := attemptsOfKind(attempts, models.AttemptPublicExample)
+	internalExample := attemptsOfKind(attempts, models.AttemptInternalExample)
+
+	if healthOK {
+		if a, ok := firstPassed(internalHealth); ok && svc.Port > 0 && a.Port != svc.Port {
+			return models.RootCauseWrongPort, fmt.Sprintf("Health answered on port %d, configured port is %d", a.Port, svc.Port)
+		}
+		if len(publicExample) == 0 || !publicExample[0].Failed() {
+			return models.RootCauseNone, "All checks passed"
+		}
+		pe := publicExample[0]
+		if _, ok := firstPassed(internalExample); !ok {
+			if a, answered := firstAnswered(internalExample); answered {
+				return models.RootCauseAppError, fmt.Sprintf("Example endpoint fails internally too (%s)", a.Error)
+			}
+		}
+		return publicCause(pe)
+	}
+
+	// The container answered, but not with a passing health response
+	if a, ok := firstAnswered(internalHealth); ok {
+		if svc.Port > 0 && a.Port != svc.Port {
+			return models.RootCauseWrongPort, fmt.Sprintf("Nothing answers on port %d, %s:%d does (%s)", svc.Port, a.Host, a.Port, a.Error)
+		}
+		return models.RootCauseAppError, fmt.Sprintf("%s:%d answered %s", a.Host, a.Port, a.Error)
+	}
+
+	if svc.NetworkStatus == "missing" {
+		return models.RootCauseNetwork, "Container is not attached to the dashboard's Docker network"
+	}
+	if a, ok := firstWithClass(internalHealth, models.ErrClassRefused); ok {
+		return models.RootCauseContainerDown, fmt.Sprintf("Connection refused by %s:%d", a.Host, a.Port)
+	}
+	if a, ok := firstWithClass(internalHealth, models.ErrClassTimeout); ok {
+		return models.RootCauseTimeout, fmt.Sprintf("%s:%d did not respond in time", a.Host, a.Port)
+	}
+	if len(internalHealth) > 0 && allClass(internalHealth, models.ErrClassDNS) {
+		return models.RootCauseContainerDown, fmt.Sprintf("Container %s does not resolve (stopped or removed?)", containerHost(svc, internalHealth))
+	}
+
+	// No internal evidence: fall back to what the public URL said
+	if len(publicHealth) > 0 && publicHealth[0].Failed() {
+		return publicCause(publicHealth[0])
+	}
+	for _, a := range internalHealth {
+		if a.Failed() {
+			return models.RootCauseUnknown, a.Error
+		}
+	}
+	return models.RootCauseUnknown, "No attempt could be made"
+}
+
+// publicCause explains a failing public request when the container is fine
+func publicCause(a models.Attempt) (string, string) {
+	switch a.ErrorClass {
+	case models.ErrClassDNS:
+		return models.RootCauseDNS, fmt.Sprintf("%s does not resolve", a.Host)
+	case models.ErrClassTLS:
+		return models.RootCauseTLS, fmt.Sprintf("TLS failure on %s: %s", a.Host, a.Error)
+	}
+	switch a.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return models.RootCauseProxy, fmt.Sprintf("Proxy for %s returned HTTP %d", a.Host, a.StatusCode)
+	}
+	if strings.Contains(a.ContentType, "text/html") {
+		return models.RootCauseProxy, fmt.Sprintf("Proxy for %s served an HTML page (HTTP %d)", a.Host, a.StatusCode)
+	}
+	return models.RootCauseProxy, fmt.Sprintf("Public URL fails while the container is reachable: %s", a.Error)
+}
+
+func attemptsOfKind(attempts []models.Attempt, kind string) []models.Attempt {
+	out := []models.Attempt{}
+	for _, a := range attempts {
+		if a.Kind == kind {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func firstPassed(attempts []models.Attempt) (models.Attempt, bool) {
+	for _, a := range attempts {
+		if !a.Failed() {
+			return a, true
+		}
+	}
+	return models.Attempt{}, false
+}
+
+// firstAnswered returns the first attempt that got an HTTP response
+func firstAnswered(attempts []models.Attempt) (models.Attempt, bool) {
+	for _, a := range attempts {
+		if a.StatusCode > 0 {
+			return a, true
+		}
+	}
+	return models.Attempt{}, false
+}
+
+func firstWithClass(attempts []models.Attempt, class string) (models.Attempt, bool) {
+	for _, a := range attempts {
+		if a.ErrorClass == class {
+			return a, true
+		}
+	}
+	return models.Attempt{}, false
+}
+
+func allClass(attempts []models.Attempt, class string) bool {
+	for _, a := range attempts {
+		if a.ErrorClass != class {
+			return false
+		}
+	}
+	return true
+}
+
+// containerHost picks the container name among the hosts tried
+func containerHost(svc *models.Service, attempts []models.Attempt) string {
+	for _, a := range attempts {
+		if a.Host != "host.docker.internal" {
+			return a.Host
+		}
+	}
+	return svc.ID
+}
@@ -0,0 +1,148 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Attempt fixtures
+func internalOK(host string, port int) models.Attempt {
+	return models.Attempt{Kind: models.AttemptInternalHealth, Host: host, Port: port, StatusCode: 200}
+}
+
+func internalFail(host string, port int, class string, status int) models.Attempt {
+	return models.Attempt{Kind: models.AttemptInternalHealth, Host: host, Port: port, StatusCode: status, ErrorClass: class, Error: class}
+}
+
+func publicFail(kind string, class string, status int, contentType string) models.Attempt {
+	return models.Attempt{Kind: kind, Host: "svc.example.com", Port: 443, StatusCode: status, ContentType: contentType, ErrorClass: class, Error: class}
+}
+
+func TestClassify(t *testing.T) {
+	const host = "go_svc"
+	tests := []struct {
+		name     string
+		network  string // Service NetworkStatus
+		attempts []models.Attempt
+		healthOK bool
+		want     string
+	}{
+		{
+			name:     "all passed",
+			attempts: []models.Attempt{internalOK(host, 8101)},
+			healthOK: true,
+			want:     models.RootCauseNone,
+		},
+		{
+			name:     "healthy on another port",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassRefused, 0), internalOK(host, 8080)},
+			healthOK: true,
+			want:     models.RootCauseWrongPort,
+		},
+		{
+			name:     "answers only on another port",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassRefused, 0), internalFail(host, 8080, models.ErrClassHTTP4xx, 404)},
+			want:     models.RootCauseWrongPort,
+		},
+		{
+			name:     "app error",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassHTTP5xx, 500)},
+			want:     models.RootCauseAppError,
+		},
+		{
+			name: "example fails internally too",
+			attempts: []models.Attempt{
+				internalOK(host, 8101),
+				publicFail(models.AttemptPublicExample, models.ErrClassHTTP5xx, 500, "application/json"),
+				{Kind: models.AttemptInternalExample, Host: host, Port: 8101, StatusCode: 500, ErrorClass: models.ErrClassHTTP5xx, Error: "HTTP 500"},
+			},
+			healthOK: true,
+			want:     models.RootCauseAppError,
+		},
+		{
+			name: "example fails only publicly",
+			attempts: []models.Attempt{
+				internalOK(host, 8101),
+				publicFail(models.AttemptPublicExample, models.ErrClassHTTP5xx, 502, "text/plain"),
+				{Kind: models.AttemptInternalExample, Host: host, Port: 8101, StatusCode: 200},
+			},
+			healthOK: true,
+			want:     models.RootCauseProxy,
+		},
+		{
+			name:     "network missing",
+			network:  "missing",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassDNS, 0)},
+			want:     models.RootCauseNetwork,
+		},
+		{
+			name:     "refused",
+			attempts: []models.Attempt{internalFail("host.docker.internal", 8101, models.ErrClassTimeout, 0), internalFail(host, 8101, models.ErrClassRefused, 0)},
+			want:     models.RootCauseContainerDown,
+		},
+		{
+			name:     "timeout",
+			attempts: []models.Attempt{internalFail("host.docker.internal", 8101, models.ErrClassTimeout, 0), internalFail(host, 8101, models.ErrClassDNS, 0)},
+			want:     models.RootCauseTimeout,
+		},
+		{
+			name:     "container does not resolve",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassDNS, 0), internalFail(host+"-app-1", 8101, models.ErrClassDNS, 0)},
+			want:     models.RootCauseContainerDown,
+		},
+		{
+			name:     "public fallback",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassReset, 0), publicFail(models.AttemptPublicHealth, models.ErrClassHTTP5xx, 503, "text/plain")},
+			want:     models.RootCauseProxy,
+		},
+		{
+			name:     "public DNS",
+			attempts: []models.Attempt{publicFail(models.AttemptPublicHealth, models.ErrClassDNS, 0, "")},
+			want:     models.RootCauseDNS,
+		},
+		{
+			name:     "internal error without class match",
+			attempts: []models.Attempt{internalFail(host, 8101, models.ErrClassReset, 0)},
+			want:     models.RootCauseUnknown,
+		},
+		{
+			name: "no attempt",
+			want: models.RootCauseUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &models.Service{ID: "svc", Port: 8101, NetworkStatus: tt.network}
+			got, summary := classify(svc, tt.attempts, tt.healthOK)
+			if got != tt.want {
+				t.Errorf("got %s (%s), want %s", got, summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicCause(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt models.Attempt
+		want    string
+		summary string
+	}{
+		{name: "dns", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassDNS, 0, ""), want: models.RootCauseDNS, summary: "svc.example.com does not resolve"},
+		{name: "tls", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassTLS, 0, ""), want: models.RootCauseTLS, summary: "TLS failure on svc.example.com: tls"},
+		{name: "502", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassHTTP5xx, 502, ""), want: models.RootCauseProxy, summary: "Proxy for svc.example.com returned HTTP 502"},
+		{name: "503", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassHTTP5xx, 503, ""), want: models.RootCauseProxy, summary: "Proxy for svc.example.com returned HTTP 503"},
+		{name: "504", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassHTTP5xx, 504, ""), want: models.RootCauseProxy, summary: "Proxy for svc.example.com returned HTTP 504"},
+		{name: "html", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassRejected, 200, "text/html; charset=utf-8"), want: models.RootCauseProxy, summary: "Proxy for svc.example.com served an HTML page (HTTP 200)"},
+		{name: "other", attempt: publicFail(models.AttemptPublicHealth, models.ErrClassHTTP4xx, 404, "application/json"), want: models.RootCauseProxy, summary: "Public URL fails while the container is reachable: http_4xx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, summary := publicCause(tt.attempt)
+			if got != tt.want || summary != tt.summary {
+				t.Errorf("got %s %q, want %s %q", got, summary, tt.want, tt.summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// attemptTrace collects httptrace callbacks, which may fire from the transport's dial goroutine
+type attemptTrace struct {
+	mu       sync.Mutex
+	attempt  models.Attempt
+	start    time.Time
+	dnsStart time.Time
+	connect  time.Time
+	tlsStart time.Time
+}
+
+func (t *attemptTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.attempt.DNSMs = time.Since(t.dnsStart).Milliseconds()
+			if info.Err != nil {
+				t.attempt.DNS = info.Err.Error()
+				return
+			}
+			addrs := make([]string, 0, len(info.Addrs))
+			for _, a := range info.Addrs {
+				addrs = append(addrs, a.String())
+			}
+			t.attempt.DNS = strings.Join(addrs, ",")
+		},
+		ConnectStart: func(_, _ string) {
+			t.mu.Lock()
+			t.connect = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			if err == nil {
+				t.attempt.ConnectMs = time.Since(t.connect).Milliseconds()
+			}
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			t.mu.Lock()
+			t.attempt.TLSMs = time.Since(t.tlsStart).Milliseconds()
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.attempt.Reused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.attempt.TTFBMs = time.Since(t.start).Milliseconds()
+			t.mu.Unlock()
+		},
+	}
+}
+
+// doTraced sends req and records where the time went and how it failed.
+// A response with a 5xx status is returned together with a failed attempt.
+func doTraced(client *http.Client, req *http.Request, kind string) (*http.Response, models.Attempt, error) {
+	t := &attemptTrace{start: time.Now(), attempt: models.Attempt{
+		Kind: kind,
+		URL:  req.URL.String(),
+		Host: req.URL.Hostname(),
+		Port: urlPort(req.URL.Port(), req.URL.Scheme),
+	}}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), t.clientTrace()))
+
+	resp, err := client.Do(req)
+	elapsed := time.Since(t.start).Milliseconds()
+
+	t.mu.Lock()
+	a := t.attempt
+	t.mu.Unlock()
+	a.ElapsedMs = elapsed
+
+	if err != nil {
+		a.Error = err.Error()
+		a.ErrorClass = classifyError(err)
+		return nil, a, err
+	}
+	a.StatusCode = resp.StatusCode
+	a.ContentType = resp.Header.Get("Content-Type")
+	switch {
+	case resp.StatusCode >= 500:
+		a.ErrorClass = models.ErrClassHTTP5xx
+		a.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		a.ErrorClass = models.ErrClassHTTP4xx
+		a.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return resp, a, nil
+}
+
+// rejectAttempt marks an attempt whose response failed the check spec
+func rejectAttempt(a *models.Attempt, reason string) {
+	a.Error = reason
+	if a.ErrorClass == "" {
+		a.ErrorClass = models.ErrClassRejected
+	}
+}
+
+// classifyError maps a transport error to an Attempt error class
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuth x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return models.ErrClassDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownAuth), errors.As(err, &hostErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr), strings.Contains(err.Error(), "tls: "):
+		return models.ErrClassTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return models.ErrClassRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return models.ErrClassTimeout
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return models.ErrClassReset
+	}
+	return models.ErrClassOther
+}
+
+func urlPort(port, scheme string) int {
+	if n, err := strconv.Atoi(port); err == nil {
+		return n
+	}
+	if scheme == "https" {
+		return 443
+	}
+	return 80
+}
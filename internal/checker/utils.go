@@ -18,6 +18,9 @@ func GetInternalHosts(svc *models.Service) []string {
 	if svc.DockerName != "" {
 		names = append(names, svc.DockerName)
 	}
+	if svc.ContainerName != "" {
+		names = append(names, svc.ContainerName)
+	}
 	if svc.ID != "" && svc.ID != svc.DockerName {
 		names = append(names, svc.ID+"-app-1")
 	}
@@ -65,22 +68,45 @@ func GetPathFromURL(rawURL string) string {
 }
 
 // TryInternalRequest attempts to reach the service via internal Docker DNS
-// Returns response on first success (200-399 range) or last error
+// Returns response on first answer below 500 or last error
 func TryInternalRequest(client *http.Client, svc *models.Service, path string) (*http.Response, string, error) {
+	res, err := tryInternal(client, svc, models.AttemptInternalHealth, http.MethodGet, path, nil, "", nil)
+	return res.Resp, res.URL, err
+}
+
+// internalResult is the outcome of tryInternal
+type internalResult struct {
+	Resp      *http.Response
+	URL       string
+	ElapsedMs int64            // Duration of the winning request
+	Attempts  []models.Attempt // Every URL tried, in order
+}
+
+// tryInternal is TryInternalRequest with a custom method, headers and body.
+// kind labels the recorded attempts. Any status below 500 is an answer, and so
+// is a 5xx listed in expected.
+func tryInternal(client *http.Client, svc *models.Service, kind, method, path string, headers map[string]string, body string, expected []int) (internalResult, error) {
 	hosts := GetInternalHosts(svc)
 	ports := GetInternalPorts(svc)
+	var res internalResult
 	var lastErr error
-	var triedURLs []string
 
 	for _, host := range hosts {
 		for _, port := range ports {
 			targetURL := fmt.Sprintf("http://%s:%d%s", host, port, path)
-			triedURLs = append(triedURLs, targetURL)
 
-			resp, err := client.Get(targetURL)
+			req, err := newRequest(method, targetURL, headers, body)
+			if err != nil {
+				return res, err
+			}
+			resp, attempt, err := doTraced(client, req, kind)
+			res.Attempts = append(res.Attempts, attempt)
 			if err == nil {
-				if resp.StatusCode >= 200 && resp.StatusCode < 500 {
-					return resp, targetURL, nil
+				if statusExpected(resp.StatusCode, expected) || (resp.StatusCode >= 200 && resp.StatusCode < 500) {
+					res.Resp = resp
+					res.URL = targetURL
+					res.ElapsedMs = attempt.ElapsedMs
+					return res, nil
 				}
 				resp.Body.Close()
 				lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
@@ -89,5 +115,5 @@ func TryInternalRequest(client *http.Client, svc *models.Service, path string) (
 			}
 		}
 	}
-	return nil, "", lastErr
+	return res, lastErr
 }
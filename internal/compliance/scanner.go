@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/baditaflorin/go_services_dashboard/internal/checker"
 	"github.com/baditaflorin/go_services_dashboard/internal/models"
 )
 
@@ -65,6 +66,8 @@ func Scan(client *http.Client, svc *models.Service) ComplianceReport {
 					report.HealthFormat = ValidationResult{Passed: false, Reason: "Invalid JSON or Non-Standard Format"}
 				} else if h.Status == "" || h.Service == "" {
 					report.HealthFormat = ValidationResult{Passed: false, Reason: "Missing standard keys (status, service)"}
+				} else if !knownHealthValue(h.Status, checker.ResolveCheckSpec(svc)) {
+					report.HealthFormat = ValidationResult{Passed: false, Reason: fmt.Sprintf("Non-standard status %q (use ok, healthy, degraded, unhealthy or declare it in status_values)", h.Status)}
 				} else {
 					report.HealthFormat = ValidationResult{Passed: true}
 					score++
@@ -86,3 +89,20 @@ func Scan(client *http.Client, svc *models.Service) ComplianceReport {
 	report.TotalScore = int((float64(score) / float64(maxScore)) * 100)
 	return report
 }
+
+// knownHealthValue reports whether a /health "status" maps onto a dashboard
+// status, either by convention or through the service's check spec
+func knownHealthValue(v string, spec models.CheckSpec) bool {
+	switch v {
+	case "ok", models.StatusHealthy, models.StatusDegraded, models.StatusUnhealthy:
+		return true
+	}
+	for _, values := range [][]string{spec.StatusValues, spec.DegradedValues} {
+		for _, known := range values {
+			if v == known {
+				return true
+			}
+		}
+	}
+	return false
+}
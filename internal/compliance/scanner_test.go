@@ -0,0 +1,34 @@
+package compliance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestScanHealthStatusValues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"up","service":"svc","version":"1.0.0"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name  string
+		check *models.CheckSpec
+		want  bool
+	}{
+		{name: "default spec", want: false},
+		{name: "declared status value", check: &models.CheckSpec{StatusValues: []string{"up"}}, want: true},
+		{name: "declared degraded value", check: &models.CheckSpec{DegradedValues: []string{"up"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Scan(srv.Client(), &models.Service{ID: "svc", Port: 8101, HealthURL: srv.URL, Check: tt.check})
+			if report.HealthFormat.Passed != tt.want {
+				t.Errorf("got health format %+v, want passed %v", report.HealthFormat, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/alerting"
+)
+
+// LoadAlerting reads config/alerts.json.
+// ${VAR} references are expanded from the environment so tokens and
+// passwords can stay out of the file. A missing file disables alerting.
+func LoadAlerting() (alerting.Config, bool, error) {
+	var cfg alerting.Config
+	content, err := readConfigFile("alerts.json")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, false, nil
+		}
+		return cfg, false, err
+	}
+	if err := json.Unmarshal([]byte(os.ExpandEnv(string(content))), &cfg); err != nil {
+		return cfg, false, fmt.Errorf("parse alerts.json: %w", err)
+	}
+	return cfg, true, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// ReadCategories parses config/categories.json. A missing file yields no metadata.
+func ReadCategories() ([]models.CategoryMeta, error) {
+	content, err := readConfigFile("categories.json")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var config struct {
+		Categories []models.CategoryMeta `json:"categories"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("parsing categories.json: %w", err)
+	}
+	if err := validatePortRanges(config.Categories); err != nil {
+		return nil, fmt.Errorf("categories.json: %w", err)
+	}
+	return config.Categories, nil
+}
+
+// validatePortRanges rejects inverted ranges and ranges claimed by two categories
+func validatePortRanges(categories []models.CategoryMeta) error {
+	var ranged []models.CategoryMeta
+	for _, c := range categories {
+		if c.PortMin == 0 && c.PortMax == 0 {
+			continue
+		}
+		if c.PortMin <= 0 || c.PortMax < c.PortMin {
+			return fmt.Errorf("category %s: invalid port range %d-%d", c.Name, c.PortMin, c.PortMax)
+		}
+		ranged = append(ranged, c)
+	}
+	sort.Slice(ranged, func(i, j int) bool { return ranged[i].PortMin < ranged[j].PortMin })
+	for i := 1; i < len(ranged); i++ {
+		prev, cur := ranged[i-1], ranged[i]
+		if cur.PortMin <= prev.PortMax {
+			return fmt.Errorf("port ranges of %s (%d-%d) and %s (%d-%d) overlap",
+				prev.Name, prev.PortMin, prev.PortMax, cur.Name, cur.PortMin, cur.PortMax)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestValidatePortRanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []models.CategoryMeta
+		wantErr    string
+	}{
+		{name: "disjoint", categories: []models.CategoryMeta{
+			{Name: "b", PortMin: 8120, PortMax: 8139},
+			{Name: "a", PortMin: 8100, PortMax: 8119},
+			{Name: "unranged"},
+		}},
+		{name: "overlap", categories: []models.CategoryMeta{
+			{Name: "security", PortMin: 8140, PortMax: 8169},
+			{Name: "domains", PortMin: 8150, PortMax: 8199},
+		}, wantErr: "security (8140-8169) and domains (8150-8199) overlap"},
+		{name: "shared bound", categories: []models.CategoryMeta{
+			{Name: "a", PortMin: 8100, PortMax: 8119},
+			{Name: "b", PortMin: 8119, PortMax: 8139},
+		}, wantErr: "overlap"},
+		{name: "inverted", categories: []models.CategoryMeta{
+			{Name: "a", PortMin: 8119, PortMax: 8100},
+		}, wantErr: "invalid port range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePortRanges(tt.categories)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExampleCategoriesAreValid(t *testing.T) {
+	content, err := os.ReadFile("../../config/categories.example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Categories []models.CategoryMeta `json:"categories"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		t.Fatal(err)
+	}
+	if err := validatePortRanges(config.Categories); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,42 +2,59 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/baditaflorin/go_services_dashboard/internal/models"
 )
 
-func LoadServices(registry *models.Registry) {
-	// Add self
-	registry.AddService(&models.Service{
+// findConfigFile looks up a file in the config directory.
+// Try multiple paths for robustness (container vs local)
+func findConfigFile(name string) (string, error) {
+	paths := []string{"config/" + name, "../config/" + name, "./" + name}
+	var err error
+
+	for _, p := range paths {
+		if _, err = os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", err
+}
+
+// readConfigFile reads a file found by findConfigFile
+func readConfigFile(name string) ([]byte, error) {
+	path, err := findConfigFile(name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err == nil {
+		log.Printf("Loaded config from %s", path)
+	}
+	return content, err
+}
+
+// selfService is the dashboard's own entry, always present in the registry
+func selfService() models.Service {
+	return models.Service{
 		ID:          "services-dashboard",
 		Name:        "services-dashboard",
 		Category:    "domains",
 		Port:        8131, // Internal port really, externally 43565 often mapped
-		Status:      "unknown",
+		Status:      models.StatusUnknown,
 		HealthURL:   "http://localhost:43565/health", // Self check
 		Description: "The main dashboard",
 		Tags:        []string{"dashboard", "infrastructure"},
-	})
-
-	// Load from config/services.json
-	// Try multiple paths for robustness (container vs local)
-	paths := []string{"config/services.json", "../config/services.json", "./services.json"}
-	var content []byte
-	var err error
-
-	for _, p := range paths {
-		content, err = os.ReadFile(p)
-		if err == nil {
-			log.Printf("Loaded config from %s", p)
-			break
-		}
 	}
+}
 
+// ReadServices parses config/services.json (object with "services" or a bare array)
+func ReadServices() ([]models.Service, error) {
+	content, err := readConfigFile("services.json")
 	if err != nil {
-		log.Printf("Error reading config file: %v", err)
-		return
+		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
 	var config struct {
@@ -51,16 +68,32 @@ func LoadServices(registry *models.Registry) {
 		if err2 := json.Unmarshal(content, &services); err2 == nil {
 			config.Services = services
 		} else {
-			log.Printf("Error parsing config file: %v", err)
-			return
+			return nil, fmt.Errorf("parsing config file: %w", err)
 		}
 	}
+	return config.Services, nil
+}
+
+func LoadServices(registry *models.Registry) {
+	// Add self
+	registry.Add(selfService())
+
+	// Category metadata is optional
+	if categories, err := ReadCategories(); err != nil {
+		log.Printf("Error loading categories: %v", err)
+	} else {
+		registry.SetCategories(categories)
+	}
+
+	// Load from config/services.json
+	services, err := ReadServices()
+	if err != nil {
+		log.Printf("Error loading services: %v", err)
+		return
+	}
 
-	for i := range config.Services {
-		// Take address of index to avoid pointer sharing issues in loops if we weren't careful,
-		// but here we just pass a pointer to the generic AddService
-		s := config.Services[i]
-		registry.AddService(&s)
+	for _, s := range services {
+		registry.Add(s)
 	}
-	log.Printf("Loaded %d services from config", len(config.Services))
+	log.Printf("Loaded %d services from config", len(services))
 }
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Reloader re-reads config/services.json and syncs it into the registry
+type Reloader struct {
+	registry *models.Registry
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader creates a reloader. Changes reach the monitor and other
+// consumers through the registry's event stream.
+func NewReloader(registry *models.Registry) *Reloader {
+	r := &Reloader{registry: registry}
+	r.modTime, r.size = r.stat()
+	return r
+}
+
+// Reload syncs the registry with the config file.
+// On read or parse errors the registry is left untouched.
+func (r *Reloader) Reload() (models.SyncResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	services, err := ReadServices()
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	r.modTime, r.size = r.stat()
+
+	if categories, err := ReadCategories(); err != nil {
+		log.Printf("Error loading categories: %v", err)
+	} else {
+		r.registry.SetCategories(categories)
+	}
+
+	desired := append([]models.Service{selfService()}, services...)
+	res := r.registry.Sync(desired)
+	log.Printf("Reloaded services config: %d added, %d updated, %d removed, %d unchanged",
+		len(res.Added), len(res.Updated), len(res.Removed), res.Unchanged)
+	return res, nil
+}
+
+// Watch polls the config file and reloads when it changes.
+// It is meant to run in its own goroutine.
+func (r *Reloader) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		modTime, size := r.stat()
+		r.mu.Lock()
+		changed := !modTime.IsZero() && (!modTime.Equal(r.modTime) || size != r.size)
+		r.mu.Unlock()
+		if !changed {
+			continue
+		}
+		if _, err := r.Reload(); err != nil {
+			log.Printf("Config reload failed: %v", err)
+			// Remember the broken version so we don't retry until it changes again
+			r.mu.Lock()
+			r.modTime, r.size = modTime, size
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *Reloader) stat() (time.Time, int64) {
+	path, err := findConfigFile("services.json")
+	if err != nil {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
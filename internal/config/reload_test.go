@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// configDir runs the test from a temp dir holding config/ and returns a
+// function writing config/services.json
+func configDir(t *testing.T) func(content string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "config", "services.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// nextEvents reads n events, failing after a second
+func nextEvents(t *testing.T, events <-chan models.Event, n int) []string {
+	t.Helper()
+	var got []string
+	for len(got) < n {
+		select {
+		case ev := <-events:
+			if ev.Kind != models.EventUpdated {
+				got = append(got, ev.Kind+":"+ev.Service.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("got events %v, want %d", got, n)
+		}
+	}
+	return got
+}
+
+func TestReload(t *testing.T) {
+	write := configDir(t)
+	write(`{"services": [{"id": "a", "port": 8101}, {"id": "b", "port": 8102}]}`)
+	registry := models.NewRegistry()
+	LoadServices(registry)
+	r := NewReloader(registry)
+
+	registry.Update("a", func(s *models.Service) bool {
+		s.Status, s.ResponseMs, s.Breaker.Failures = models.StatusHealthy, 42, 2
+		return true
+	})
+	events, cancel := registry.Subscribe()
+	defer cancel()
+
+	// b removed, c added, a unchanged
+	write(`{"services": [{"id": "a", "port": 8101}, {"id": "c", "port": 8103}]}`)
+	res, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(res.Added, ",") != "c" || strings.Join(res.Removed, ",") != "b" || len(res.Updated) != 0 || res.Unchanged != 2 {
+		t.Fatalf("got %+v", res)
+	}
+	if got := strings.Join(nextEvents(t, events, 2), " "); got != "added:c removed:b" {
+		t.Errorf("got events %s", got)
+	}
+	if _, ok := registry.Get("b"); ok {
+		t.Error("b is still registered")
+	}
+	if _, ok := registry.Get("services-dashboard"); !ok {
+		t.Error("the dashboard's own entry was removed")
+	}
+
+	// A changed port reconfigures a, which keeps its runtime state
+	write(`[{"id": "a", "port": 8111}, {"id": "c", "port": 8103}]`)
+	if res, err = r.Reload(); err != nil || strings.Join(res.Updated, ",") != "a" {
+		t.Fatalf("got %+v, %v", res, err)
+	}
+	if got := strings.Join(nextEvents(t, events, 1), " "); got != "reconfigured:a" {
+		t.Errorf("got events %s", got)
+	}
+	a, _ := registry.Get("a")
+	if a.Port != 8111 || a.Status != models.StatusHealthy || a.ResponseMs != 42 || a.Breaker.Failures != 2 {
+		t.Errorf("got %+v, want the new port and the old runtime state", a)
+	}
+
+	// A broken file leaves the registry alone
+	before := registry.Len()
+	write(`{"services": [{"id": "a"`)
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("broken file reloaded")
+	}
+	if registry.Len() != before {
+		t.Errorf("got %d services, want %d", registry.Len(), before)
+	}
+	if a, _ := registry.Get("a"); a.Port != 8111 {
+		t.Errorf("got port %d after a failed reload", a.Port)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	write := configDir(t)
+	write(`[{"id": "a", "port": 8101}]`)
+	registry := models.NewRegistry()
+	LoadServices(registry)
+	r := NewReloader(registry)
+	go r.Watch(10 * time.Millisecond)
+
+	waitFor := func(what string, ok func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !ok() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	write(`[{"id": "a", "port": 8101}, {"id": "watched", "port": 8102}]`)
+	waitFor("the new service", func() bool { _, ok := registry.Get("watched"); return ok })
+
+	// Broken, then fixed: only the fixed version is applied
+	write(`[{"id": "a", "port": 8101}, {"id": "broken"`)
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := registry.Get("watched"); !ok {
+		t.Fatal("a broken file changed the registry")
+	}
+	write(`[{"id": "a", "port": 8101}]`)
+	waitFor("the removal", func() bool { _, ok := registry.Get("watched"); return !ok })
+}
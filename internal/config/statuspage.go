@@ -0,0 +1,31 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/statuspage"
+)
+
+// LoadStatusPage reads config/statuspage.json. A missing file yields the
+// default page with one component group per category.
+func LoadStatusPage() (statuspage.Config, error) {
+	var cfg statuspage.Config
+	content, err := readConfigFile("statuspage.json")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse statuspage.json: %w", err)
+	}
+	for i, g := range cfg.Groups {
+		if g.Name == "" {
+			return cfg, fmt.Errorf("statuspage.json: group %d has no name", i+1)
+		}
+	}
+	return cfg, nil
+}
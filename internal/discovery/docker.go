@@ -0,0 +1,222 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/docker"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Container labels understood by discovery. All are optional.
+const (
+	LabelID          = "dashboard.id"
+	LabelName        = "dashboard.name"
+	LabelCategory    = "dashboard.category"
+	LabelPort        = "dashboard.port"
+	LabelHealthURL   = "dashboard.health_url"
+	LabelExampleURL  = "dashboard.example_url"
+	LabelTags        = "dashboard.tags"       // Comma separated
+	LabelDependsOn   = "dashboard.depends_on" // Comma separated service IDs
+	LabelIgnore      = "dashboard.ignore"
+	labelCompose     = "com.docker.compose.project"
+	labelOCIVersion  = "org.opencontainers.image.version"
+	labelOCISource   = "org.opencontainers.image.source"
+	defaultCategory  = "discovered"
+	composeAppSuffix = "-app-1"
+)
+
+var semverTag = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)$`)
+
+// Options selects which containers become services
+type Options struct {
+	Labels          []string       // Label filters passed to the Docker API ("key" or "key=value")
+	NamePattern     *regexp.Regexp // Container names must match when set
+	PublicURLFormat string         // e.g. "https://%s.0crawl.com", used to derive health/example URLs
+	IncludeStopped  bool
+}
+
+// Provider discovers services from the Docker Engine API
+type Provider struct {
+	client   *docker.Client
+	registry *models.Registry
+	opts     Options
+}
+
+// NewProvider creates a Docker discovery provider. Changes reach the monitor
+// and other consumers through the registry's event stream.
+func NewProvider(client *docker.Client, registry *models.Registry, opts Options) *Provider {
+	return &Provider{client: client, registry: registry, opts: opts}
+}
+
+// Discover lists matching containers and converts them into services
+func (p *Provider) Discover(ctx context.Context) ([]models.Service, error) {
+	containers, err := p.client.ListContainers(ctx, p.opts.IncludeStopped, p.opts.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]models.Service, 0, len(containers))
+	seen := make(map[string]bool)
+	for _, c := range containers {
+		if c.Labels[LabelIgnore] == "true" {
+			continue
+		}
+		if p.opts.NamePattern != nil && !p.opts.NamePattern.MatchString(c.Name()) {
+			continue
+		}
+		svc := p.serviceFromContainer(c)
+		if svc.ID == "" || seen[svc.ID] {
+			continue
+		}
+		seen[svc.ID] = true
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].ID < services[j].ID })
+	return services, nil
+}
+
+// Sync runs one discovery pass and merges the result into the registry
+func (p *Provider) Sync(ctx context.Context) (models.SyncResult, error) {
+	services, err := p.Discover(ctx)
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	res := p.registry.MergeDiscovered(services)
+	if len(res.Added)+len(res.Removed) > 0 {
+		log.Printf("Docker discovery: %d containers, %d added, %d removed", len(services), len(res.Added), len(res.Removed))
+	}
+	return res, nil
+}
+
+// Run syncs periodically. It is meant to run in its own goroutine.
+func (p *Provider) Run(interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := p.Sync(ctx); err != nil {
+			log.Printf("Docker discovery failed: %v", err)
+		}
+		cancel()
+		time.Sleep(interval)
+	}
+}
+
+func (p *Provider) serviceFromContainer(c docker.Container) models.Service {
+	name := c.Name()
+	labels := c.Labels
+
+	id := labels[LabelID]
+	if id == "" {
+		id = labels[labelCompose]
+	}
+	if id == "" {
+		id = strings.TrimSuffix(name, composeAppSuffix)
+	}
+
+	category := labels[LabelCategory]
+	if category == "" {
+		category = defaultCategory
+	}
+
+	svc := models.Service{
+		ID:             id,
+		Name:           id,
+		DisplayName:    labels[LabelName],
+		Category:       category,
+		Port:           containerPort(c),
+		DockerName:     name,
+		ContainerName:  name,
+		Image:          c.Image,
+		ContainerState: c.State,
+		Networks:       c.NetworkNames(),
+		Version:        imageVersion(c),
+		RepoURL:        labels[labelOCISource],
+		HealthURL:      labels[LabelHealthURL],
+		ExampleURL:     labels[LabelExampleURL],
+		Status:         models.StatusUnknown,
+		Tags:           []string{"docker"},
+	}
+	sort.Strings(svc.Networks)
+
+	if svc.DisplayName == "" {
+		svc.DisplayName = titleCase(strings.ReplaceAll(strings.TrimPrefix(id, "go_"), "_", " "))
+	}
+	if svc.HealthURL == "" && p.opts.PublicURLFormat != "" {
+		svc.HealthURL = fmt.Sprintf(p.opts.PublicURLFormat, PublicName(id)) + "/health"
+	}
+	if tags := labels[LabelTags]; tags != "" {
+		for _, t := range strings.Split(tags, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				svc.Tags = append(svc.Tags, t)
+			}
+		}
+	}
+	if deps := labels[LabelDependsOn]; deps != "" {
+		for _, d := range strings.Split(deps, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				svc.DependsOn = append(svc.DependsOn, d)
+			}
+		}
+	}
+	if category != defaultCategory {
+		svc.Tags = append(svc.Tags, category)
+	}
+	return svc
+}
+
+// titleCase upper-cases the first letter of every word: "phone extractor" -> "Phone Extractor"
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// PublicName maps a service ID to its public hostname part: go_phone_extractor -> phone-extractor
+func PublicName(id string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(id, "go_"), "_", "-")
+}
+
+// containerPort prefers the dashboard.port label, then a published port, then an exposed one
+func containerPort(c docker.Container) int {
+	if v := c.Labels[LabelPort]; v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	for _, p := range c.Ports {
+		if p.PublicPort > 0 && p.Type == "tcp" {
+			return p.PublicPort
+		}
+	}
+	for _, p := range c.Ports {
+		if p.Type == "tcp" {
+			return p.PrivatePort
+		}
+	}
+	return 0
+}
+
+// imageVersion reads the OCI version label or a semver image tag
+func imageVersion(c docker.Container) string {
+	if v := c.Labels[labelOCIVersion]; v != "" {
+		return strings.TrimPrefix(v, "v")
+	}
+	image := c.Image
+	if i := strings.LastIndex(image, ":"); i != -1 && !strings.Contains(image[i:], "/") {
+		if m := semverTag.FindStringSubmatch(image[i+1:]); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,228 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/docker"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// fakeDocker serves GET /containers/json on a unix socket
+type fakeDocker struct {
+	mu         sync.Mutex
+	containers []docker.Container
+	filters    string
+}
+
+func (f *fakeDocker) set(containers ...docker.Container) {
+	f.mu.Lock()
+	f.containers = containers
+	f.mu.Unlock()
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != "/containers/json" {
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filters = r.URL.Query().Get("filters")
+	json.NewEncoder(w).Encode(f.containers)
+}
+
+func newFakeDocker(t *testing.T) (*fakeDocker, *docker.Client) {
+	t.Helper()
+	// Unix socket paths are short; t.TempDir() can exceed the limit
+	dir, err := os.MkdirTemp("", "dock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeDocker{}
+	srv := &http.Server{Handler: fake}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return fake, docker.NewClient("unix://" + socket)
+}
+
+func container(name string, labels map[string]string) docker.Container {
+	c := docker.Container{
+		ID:     name + "-id",
+		Names:  []string{"/" + name},
+		Image:  "ghcr.io/baditaflorin/" + name + ":v1.2.3",
+		Labels: labels,
+		State:  "running",
+		Ports:  []docker.Port{{PrivatePort: 8080, PublicPort: 8101, Type: "tcp"}},
+	}
+	c.NetworkSettings.Networks = map[string]docker.EndpointSettings{"pentest_network": {}, "bridge": {}}
+	return c
+}
+
+func TestDiscover(t *testing.T) {
+	fake, client := newFakeDocker(t)
+	fake.set(
+		container("go_phone_extractor-app-1", map[string]string{"com.docker.compose.project": "go_phone_extractor"}),
+		container("go_ignored-app-1", map[string]string{LabelIgnore: "true"}),
+		container("postgres", nil),
+		container("go_custom", map[string]string{
+			LabelID:         "custom",
+			LabelName:       "Custom One",
+			LabelCategory:   "tools",
+			LabelPort:       "9000",
+			LabelTags:       "a, b",
+			LabelDependsOn:  "go_phone_extractor",
+			labelOCIVersion: "v2.0.0",
+		}),
+	)
+
+	p := NewProvider(client, models.NewRegistry(), Options{
+		Labels:          []string{"dashboard.enabled=true"},
+		NamePattern:     regexp.MustCompile(`^go_`),
+		PublicURLFormat: "https://%s.0crawl.com",
+	})
+	services, err := p.Discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.filters != `{"label":["dashboard.enabled=true"]}` {
+		t.Errorf("got filters %q", fake.filters)
+	}
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2: %+v", len(services), services)
+	}
+
+	custom, phone := services[0], services[1]
+	if custom.ID != "custom" || custom.DisplayName != "Custom One" || custom.Category != "tools" ||
+		custom.Port != 9000 || custom.Version != "2.0.0" || custom.ContainerName != "go_custom" {
+		t.Errorf("got %+v", custom)
+	}
+	if len(custom.Tags) != 4 || custom.Tags[1] != "a" || custom.Tags[3] != "tools" {
+		t.Errorf("got tags %v", custom.Tags)
+	}
+	if len(custom.DependsOn) != 1 || custom.DependsOn[0] != "go_phone_extractor" {
+		t.Errorf("got depends_on %v", custom.DependsOn)
+	}
+
+	if phone.ID != "go_phone_extractor" || phone.DisplayName != "Phone Extractor" ||
+		phone.Category != defaultCategory || phone.Port != 8101 || phone.Version != "1.2.3" ||
+		phone.HealthURL != "https://phone-extractor.0crawl.com/health" ||
+		phone.ContainerName != "go_phone_extractor-app-1" || phone.DockerName != "go_phone_extractor-app-1" {
+		t.Errorf("got %+v", phone)
+	}
+	if len(phone.Networks) != 2 || phone.Networks[0] != "bridge" {
+		t.Errorf("got networks %v", phone.Networks)
+	}
+}
+
+func TestDiscoverWithoutSocket(t *testing.T) {
+	client := docker.NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+	p := NewProvider(client, models.NewRegistry(), Options{})
+	if _, err := p.Discover(context.Background()); err == nil {
+		t.Fatal("expected an error without a Docker socket")
+	}
+}
+
+func TestSyncIsStableAfterChecks(t *testing.T) {
+	fake, client := newFakeDocker(t)
+	fake.set(container("go_a-app-1", nil), container("go_b-app-1", nil))
+	registry := models.NewRegistry()
+	p := NewProvider(client, registry, Options{})
+
+	res, err := p.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Added) != 2 {
+		t.Fatalf("got added %v, want both containers", res.Added)
+	}
+
+	// The checker reaches both through the host gateway
+	for _, id := range []string{"go_a", "go_b"} {
+		registry.Update(id, func(s *models.Service) bool {
+			s.DockerName = "host.docker.internal"
+			return true
+		})
+	}
+
+	events, cancel := registry.Subscribe()
+	defer cancel()
+	res, err = p.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Added)+len(res.Updated)+len(res.Removed) != 0 || res.Unchanged != 2 {
+		t.Fatalf("got %+v, want nothing changed", res)
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected %s event for %s", ev.Kind, ev.Service.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if svc, _ := registry.Get("go_a"); svc.DockerName != "host.docker.internal" || svc.ContainerName != "go_a-app-1" {
+		t.Errorf("got docker_name %q, container_name %q", svc.DockerName, svc.ContainerName)
+	}
+
+	// A stopped container takes its discovered service with it
+	fake.set(container("go_a-app-1", nil))
+	res, err = p.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Removed) != 1 || res.Removed[0] != "go_b" {
+		t.Fatalf("got removed %v, want [go_b]", res.Removed)
+	}
+}
+
+func TestSyncMatchesByContainerName(t *testing.T) {
+	fake, client := newFakeDocker(t)
+	fake.set(container("go_x-app-1", nil))
+	registry := models.NewRegistry()
+	p := NewProvider(client, registry, Options{})
+	if _, err := p.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relabeling the container keeps it attached to the known service
+	c := container("go_x-app-1", map[string]string{LabelID: "renamed"})
+	c.Image = "ghcr.io/baditaflorin/go_x:v2.0.0"
+	fake.set(c)
+	res, err := p.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Added)+len(res.Removed) != 0 || len(res.Updated) != 1 || res.Updated[0] != "go_x" {
+		t.Fatalf("got %+v, want go_x updated", res)
+	}
+	svc, _ := registry.Get("go_x")
+	if svc.Image != c.Image || svc.Version != "1.2.3" {
+		t.Errorf("got image %q, version %q", svc.Image, svc.Version)
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"phone extractor": "Phone Extractor",
+		"ümlaut  name":    "Ümlaut Name",
+		"":                "",
+	} {
+		if got := titleCase(in); got != want {
+			t.Errorf("titleCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
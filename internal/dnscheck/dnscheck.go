@@ -0,0 +1,285 @@
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// systemResolver names the host's own resolver in answers
+const systemResolver = "system"
+
+// Options configures DNS checks
+type Options struct {
+	Resolvers     []string      // "ip:port" of resolvers to ask, empty uses the system resolver
+	ExpectedIPs   []string      // Ingress addresses every public hostname should resolve to
+	ExpectedCNAME string        // Optional CNAME target, e.g. "ingress.0crawl.com."
+	Timeout       time.Duration // Per-lookup timeout
+	Concurrency   int           // Hostnames resolved in parallel
+}
+
+// Checker resolves the public hostnames of every service
+type Checker struct {
+	registry  *models.Registry
+	opts      Options
+	resolvers map[string]*net.Resolver
+	names     []string // Resolver names in the configured order
+}
+
+// NewChecker creates a DNS checker
+func NewChecker(registry *models.Registry, opts Options) *Checker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	opts.ExpectedCNAME = normalizeName(opts.ExpectedCNAME)
+
+	c := &Checker{registry: registry, opts: opts, resolvers: make(map[string]*net.Resolver)}
+	if len(opts.Resolvers) == 0 {
+		c.resolvers[systemResolver] = net.DefaultResolver
+		c.names = []string{systemResolver}
+		return c
+	}
+	for _, addr := range opts.Resolvers {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "53")
+		}
+		c.resolvers[addr] = resolverFor(addr, opts.Timeout)
+		c.names = append(c.names, addr)
+	}
+	return c
+}
+
+// resolverFor returns a pure Go resolver that only talks to addr
+func resolverFor(addr string, timeout time.Duration) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: timeout}
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// Resolve asks every resolver about host and compares the answers with the expected ingress
+func (c *Checker) Resolve(ctx context.Context, host string) models.DNSStatus {
+	status := models.DNSStatus{
+		Host:      host,
+		Expected:  c.opts.ExpectedIPs,
+		Answers:   make([]models.DNSAnswer, 0, len(c.names)),
+		CheckedAt: time.Now(),
+	}
+	for _, name := range c.names {
+		status.Answers = append(status.Answers, c.lookup(ctx, name, c.resolvers[name], host))
+	}
+	status.Status, status.Unexpected, status.Reason = c.evaluate(status.Answers)
+	return status
+}
+
+func (c *Checker) lookup(ctx context.Context, name string, r *net.Resolver, host string) models.DNSAnswer {
+	ans := models.DNSAnswer{Resolver: name}
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			ans.NotFound = true
+		} else {
+			ans.Error = err.Error()
+		}
+		return ans
+	}
+	for _, a := range addrs {
+		if a.IP.To4() != nil {
+			ans.A = append(ans.A, a.IP.String())
+		} else {
+			ans.AAAA = append(ans.AAAA, a.IP.String())
+		}
+	}
+	sort.Strings(ans.A)
+	sort.Strings(ans.AAAA)
+
+	// LookupCNAME returns the canonical name, which is host itself without a CNAME
+	if cname, err := r.LookupCNAME(ctx, host); err == nil && normalizeName(cname) != normalizeName(host) {
+		ans.CNAME = normalizeName(cname)
+	}
+	return ans
+}
+
+// evaluate derives the overall status from the per-resolver answers
+func (c *Checker) evaluate(answers []models.DNSAnswer) (string, []string, string) {
+	var resolved []models.DNSAnswer
+	notFound := 0
+	for _, a := range answers {
+		switch {
+		case a.NotFound || (a.Error == "" && len(a.A)+len(a.AAAA) == 0):
+			notFound++
+		case a.Error == "":
+			resolved = append(resolved, a)
+		}
+	}
+
+	if len(resolved) == 0 {
+		if notFound > 0 {
+			return models.DNSMissing, nil, "no A/AAAA records"
+		}
+		return models.DNSError, nil, "no resolver answered"
+	}
+	if notFound > 0 {
+		return models.DNSDrift, nil, fmt.Sprintf("%d of %d resolvers have no record", notFound, len(answers))
+	}
+
+	expected := make(map[string]bool, len(c.opts.ExpectedIPs))
+	for _, ip := range c.opts.ExpectedIPs {
+		expected[ip] = true
+	}
+	unexpected := map[string]bool{}
+	first := strings.Join(addresses(resolved[0]), ",")
+	disagree := false
+	for _, a := range resolved {
+		if strings.Join(addresses(a), ",") != first {
+			disagree = true
+		}
+		if len(expected) == 0 {
+			continue
+		}
+		for _, ip := range addresses(a) {
+			if !expected[ip] {
+				unexpected[ip] = true
+			}
+		}
+	}
+
+	if len(unexpected) > 0 {
+		list := make([]string, 0, len(unexpected))
+		for ip := range unexpected {
+			list = append(list, ip)
+		}
+		sort.Strings(list)
+		return models.DNSDrift, list, "resolves outside the expected ingress"
+	}
+	if c.opts.ExpectedCNAME != "" {
+		for _, a := range resolved {
+			if a.CNAME != c.opts.ExpectedCNAME {
+				return models.DNSDrift, nil, fmt.Sprintf("%s returned CNAME %q, expected %q", a.Resolver, a.CNAME, c.opts.ExpectedCNAME)
+			}
+		}
+	}
+	if disagree {
+		return models.DNSDrift, nil, "resolvers disagree"
+	}
+	return models.DNSOK, nil, ""
+}
+
+// CheckAll resolves every service's public hostnames and stores the results in Service.DNS
+func (c *Checker) CheckAll(ctx context.Context) {
+	type job struct {
+		id    string
+		hosts []string
+	}
+	var jobs []job
+	for _, svc := range c.registry.GetAll() {
+		if hosts := publicHosts(&svc); len(hosts) > 0 {
+			jobs = append(jobs, job{id: svc.ID, hosts: hosts})
+		}
+	}
+
+	sem := make(chan struct{}, c.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results := make([]models.DNSStatus, 0, len(j.hosts))
+			for _, host := range j.hosts {
+				res := c.Resolve(ctx, host)
+				if res.Status != models.DNSOK {
+					log.Printf("DNS %s (%s): %s %s", host, j.id, res.Status, res.Reason)
+				}
+				results = append(results, res)
+			}
+			c.registry.Update(j.id, func(s *models.Service) bool {
+				if sameStatuses(s.DNS, results) {
+					return false // Publishing every run would only move CheckedAt
+				}
+				s.DNS = results
+				return true
+			})
+		}(j)
+	}
+	wg.Wait()
+}
+
+// sameStatuses reports whether a and b differ in nothing but when they were checked
+func sameStatuses(a, b []models.DNSStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.CheckedAt, y.CheckedAt = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run checks periodically. It is meant to run in its own goroutine.
+func (c *Checker) Run(interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		c.CheckAll(ctx)
+		cancel()
+		time.Sleep(interval)
+	}
+}
+
+// publicHosts returns the distinct hostnames of HealthURL and ExampleURL, skipping IP literals
+func publicHosts(svc *models.Service) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, raw := range []string{svc.HealthURL, svc.ExampleURL} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		host := u.Hostname()
+		if host == "" || host == "localhost" || net.ParseIP(host) != nil || seen[host] {
+			continue
+		}
+		seen[host] = true
+		out = append(out, host)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// addresses returns the A and AAAA records of an answer
+func addresses(a models.DNSAnswer) []string {
+	return append(append([]string{}, a.A...), a.AAAA...)
+}
+
+func normalizeName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
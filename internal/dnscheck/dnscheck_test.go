@@ -0,0 +1,276 @@
+package dnscheck
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// DNS wire constants used by the test server
+const (
+	typeA     = 1
+	typeCNAME = 5
+	typeAAAA  = 28
+	classIN   = 1
+)
+
+// zoneRecord is what the test server knows about one name
+type zoneRecord struct {
+	a, aaaa []string
+	cname   string
+}
+
+// dnsServer is a minimal in-process authoritative DNS server over UDP
+type dnsServer struct {
+	conn net.PacketConn
+	mu   sync.Mutex
+	zone map[string]zoneRecord // Lower-case names with a trailing dot
+}
+
+func newDNSServer(t *testing.T, zone map[string]zoneRecord) *dnsServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &dnsServer{conn: conn, zone: zone}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *dnsServer) addr() string { return s.conn.LocalAddr().String() }
+
+func (s *dnsServer) set(name string, rec zoneRecord) {
+	s.mu.Lock()
+	s.zone[name] = rec
+	s.mu.Unlock()
+}
+
+func (s *dnsServer) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.answer(buf[:n]); resp != nil {
+			s.conn.WriteTo(resp, from)
+		}
+	}
+}
+
+// answer builds the response to one query, or nil for malformed ones
+func (s *dnsServer) answer(query []byte) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// The question: labels up to the root, then type and class
+	i := 12
+	var labels []string
+	for i < len(query) && query[i] != 0 {
+		l := int(query[i])
+		if i+1+l > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[i+1:i+1+l]))
+		i += 1 + l
+	}
+	if i+5 > len(query) {
+		return nil
+	}
+	question := query[12 : i+5]
+	qtype := binary.BigEndian.Uint16(query[i+1:])
+	name := strings.ToLower(strings.Join(labels, ".")) + "."
+
+	s.mu.Lock()
+	rec, ok := s.zone[name]
+	target, hasTarget := s.zone[rec.cname]
+	s.mu.Unlock()
+
+	var answers [][]byte
+	if ok && rec.cname != "" {
+		answers = append(answers, rr(pointer, typeCNAME, encodeName(rec.cname)))
+		if qtype != typeCNAME && hasTarget {
+			answers = append(answers, addressRRs(encodeName(rec.cname), qtype, target)...)
+		}
+	} else if ok {
+		answers = addressRRs(pointer, qtype, rec)
+	}
+
+	resp := make([]byte, 12, 512)
+	copy(resp, query[:2])                                  // ID
+	flags := uint16(0x8400) | uint16(query[2]&1)<<8 | 0x80 // QR, AA, RD copied, RA
+	if !ok {
+		flags |= 3 // NXDOMAIN
+	}
+	binary.BigEndian.PutUint16(resp[2:], flags)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	binary.BigEndian.PutUint16(resp[6:], uint16(len(answers)))
+	resp = append(resp, question...)
+	for _, a := range answers {
+		resp = append(resp, a...)
+	}
+	return resp
+}
+
+// pointer is a compressed name pointing at the question name
+var pointer = []byte{0xc0, 12}
+
+func addressRRs(owner []byte, qtype uint16, rec zoneRecord) [][]byte {
+	var out [][]byte
+	switch qtype {
+	case typeA:
+		for _, ip := range rec.a {
+			out = append(out, rr(owner, typeA, net.ParseIP(ip).To4()))
+		}
+	case typeAAAA:
+		for _, ip := range rec.aaaa {
+			out = append(out, rr(owner, typeAAAA, net.ParseIP(ip).To16()))
+		}
+	}
+	return out
+}
+
+func rr(owner []byte, rtype uint16, data []byte) []byte {
+	b := append([]byte{}, owner...)
+	b = binary.BigEndian.AppendUint16(b, rtype)
+	b = binary.BigEndian.AppendUint16(b, classIN)
+	b = binary.BigEndian.AppendUint32(b, 60)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+func encodeName(name string) []byte {
+	var b []byte
+	for _, l := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(l)))
+		b = append(b, l...)
+	}
+	return append(b, 0)
+}
+
+func TestResolve(t *testing.T) {
+	ingress := "203.0.113.10"
+	zone := func() map[string]zoneRecord {
+		return map[string]zoneRecord{
+			"ok.dash.test.":        {a: []string{ingress}},
+			"dual.dash.test.":      {a: []string{ingress}, aaaa: []string{"2001:db8::10"}},
+			"moved.dash.test.":     {a: []string{"198.51.100.7"}},
+			"alias.dash.test.":     {cname: "ingress.dash.test."},
+			"other.dash.test.":     {cname: "elsewhere.dash.test."},
+			"ingress.dash.test.":   {a: []string{ingress}},
+			"elsewhere.dash.test.": {a: []string{ingress}},
+			"empty.dash.test.":     {},
+		}
+	}
+	primary := newDNSServer(t, zone())
+	secondary := newDNSServer(t, zone())
+	secondary.set("split.dash.test.", zoneRecord{a: []string{ingress}})
+	primary.set("disagree.dash.test.", zoneRecord{a: []string{"192.0.2.1"}})
+	secondary.set("disagree.dash.test.", zoneRecord{a: []string{"192.0.2.2"}})
+
+	both := []string{primary.addr(), secondary.addr()}
+	tests := []struct {
+		name       string
+		host       string
+		opts       Options
+		want       string
+		unexpected []string
+	}{
+		{name: "expected ingress", host: "ok.dash.test", opts: Options{ExpectedIPs: []string{ingress}}, want: models.DNSOK},
+		{name: "AAAA outside ingress", host: "dual.dash.test", opts: Options{ExpectedIPs: []string{ingress}}, want: models.DNSDrift, unexpected: []string{"2001:db8::10"}},
+		{name: "drift", host: "moved.dash.test", opts: Options{ExpectedIPs: []string{ingress}}, want: models.DNSDrift, unexpected: []string{"198.51.100.7"}},
+		{name: "missing", host: "gone.dash.test", want: models.DNSMissing},
+		{name: "no records", host: "empty.dash.test", want: models.DNSMissing},
+		{name: "expected cname", host: "alias.dash.test", opts: Options{ExpectedCNAME: "ingress.dash.test"}, want: models.DNSOK},
+		{name: "other cname", host: "other.dash.test", opts: Options{ExpectedCNAME: "ingress.dash.test"}, want: models.DNSDrift},
+		{name: "missing on one resolver", host: "split.dash.test", want: models.DNSDrift},
+		{name: "resolvers disagree", host: "disagree.dash.test", want: models.DNSDrift},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Resolvers = both
+			c := NewChecker(models.NewRegistry(), tt.opts)
+			got := c.Resolve(context.Background(), tt.host)
+			if got.Status != tt.want {
+				t.Fatalf("got %s (%s), want %s: %+v", got.Status, got.Reason, tt.want, got.Answers)
+			}
+			if strings.Join(got.Unexpected, ",") != strings.Join(tt.unexpected, ",") {
+				t.Errorf("got unexpected %v, want %v", got.Unexpected, tt.unexpected)
+			}
+			if len(got.Answers) != 2 || got.Answers[0].Resolver != primary.addr() {
+				t.Errorf("got answers %+v", got.Answers)
+			}
+		})
+	}
+}
+
+func TestResolveCNAMEAnswer(t *testing.T) {
+	srv := newDNSServer(t, map[string]zoneRecord{
+		"alias.dash.test.":   {cname: "ingress.dash.test."},
+		"ingress.dash.test.": {a: []string{"203.0.113.10"}},
+	})
+	c := NewChecker(models.NewRegistry(), Options{Resolvers: []string{srv.addr()}})
+	got := c.Resolve(context.Background(), "alias.dash.test")
+	ans := got.Answers[0]
+	if ans.CNAME != "ingress.dash.test." || len(ans.A) != 1 || ans.A[0] != "203.0.113.10" {
+		t.Errorf("got %+v", ans)
+	}
+}
+
+func TestResolveWithoutResolver(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	c := NewChecker(models.NewRegistry(), Options{Resolvers: []string{addr}, Timeout: 500 * time.Millisecond})
+	got := c.Resolve(context.Background(), "ok.dash.test")
+	if got.Status != models.DNSError || got.Answers[0].Error == "" {
+		t.Errorf("got %s %+v, want an error", got.Status, got.Answers)
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	srv := newDNSServer(t, map[string]zoneRecord{
+		"svc.dash.test.": {a: []string{"203.0.113.10"}},
+	})
+	registry := models.NewRegistry()
+	registry.Add(models.Service{
+		ID:         "svc",
+		HealthURL:  "https://svc.dash.test/health",
+		ExampleURL: "http://127.0.0.1:8080/x", // IP literals are not resolved
+	})
+	c := NewChecker(registry, Options{Resolvers: []string{srv.addr()}, ExpectedIPs: []string{"203.0.113.10"}})
+
+	c.CheckAll(context.Background())
+	svc, _ := registry.Get("svc")
+	if len(svc.DNS) != 1 || svc.DNS[0].Host != "svc.dash.test" || svc.DNS[0].Status != models.DNSOK {
+		t.Fatalf("got %+v", svc.DNS)
+	}
+
+	// An unchanged answer does not publish an event
+	events, cancel := registry.Subscribe()
+	defer cancel()
+	c.CheckAll(context.Background())
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected %s event", ev.Kind)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	srv.set("svc.dash.test.", zoneRecord{a: []string{"198.51.100.7"}})
+	c.CheckAll(context.Background())
+	if svc, _ := registry.Get("svc"); svc.DNS[0].Status != models.DNSDrift {
+		t.Errorf("got %s after the record moved", svc.DNS[0].Status)
+	}
+}
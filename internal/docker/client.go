@@ -0,0 +1,159 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultSocket is where the Docker Engine API listens on Linux hosts
+const DefaultSocket = "/var/run/docker.sock"
+
+// Client is a minimal Docker Engine API client over a unix socket
+type Client struct {
+	http *http.Client
+	base string
+}
+
+// NewClient connects to the Docker Engine API.
+// host is a unix socket path ("/var/run/docker.sock", "unix:///...") or an
+// http(s):// / tcp:// address.
+func NewClient(host string) *Client {
+	if host == "" {
+		host = DefaultSocket
+	}
+
+	if strings.HasPrefix(host, "tcp://") {
+		host = "http://" + strings.TrimPrefix(host, "tcp://")
+	}
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return &Client{
+			http: &http.Client{Timeout: 10 * time.Second},
+			base: strings.TrimRight(host, "/"),
+		}
+	}
+
+	socket := strings.TrimPrefix(host, "unix://")
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{
+		http: &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		base: "http://docker",
+	}
+}
+
+// Port is a port mapping of a container
+type Port struct {
+	IP          string `json:"IP,omitempty"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort,omitempty"`
+	Type        string `json:"Type"`
+}
+
+// EndpointSettings describes a container's attachment to one network
+type EndpointSettings struct {
+	NetworkID string `json:"NetworkID"`
+	IPAddress string `json:"IPAddress"`
+}
+
+// Container is an entry of GET /containers/json
+type Container struct {
+	ID              string            `json:"Id"`
+	Names           []string          `json:"Names"`
+	Image           string            `json:"Image"`
+	ImageID         string            `json:"ImageID"`
+	Labels          map[string]string `json:"Labels"`
+	State           string            `json:"State"`
+	Status          string            `json:"Status"`
+	Ports           []Port            `json:"Ports"`
+	NetworkSettings struct {
+		Networks map[string]EndpointSettings `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Name returns the primary container name without the leading slash
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// NetworkNames returns the names of the networks the container is attached to
+func (c Container) NetworkNames() []string {
+	names := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ListContainers returns containers, optionally filtered by labels ("key" or "key=value")
+func (c *Client) ListContainers(ctx context.Context, all bool, labels []string) ([]Container, error) {
+	q := url.Values{}
+	if all {
+		q.Set("all", "true")
+	}
+	if len(labels) > 0 {
+		filters, _ := json.Marshal(map[string][]string{"label": labels})
+		q.Set("filters", string(filters))
+	}
+
+	var containers []Container
+	if err := c.do(ctx, http.MethodGet, "/containers/json?"+q.Encode(), nil, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var rdr io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rdr = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, rdr)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		return fmt.Errorf("docker %s %s: HTTP %d: %s", method, path, resp.StatusCode, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ConnectNetwork attaches a container (ID or name) to a network
+func (c *Client) ConnectNetwork(ctx context.Context, network, container string) error {
+	return c.do(ctx, http.MethodPost, "/networks/"+url.PathEscape(network)+"/connect", map[string]string{"Container": container}, nil)
+}
@@ -0,0 +1,367 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+const dayLayout = "2006-01-02"
+
+// Record is a single check result persisted to disk.
+// Downsampled records aggregate several checks: Samples is the number of
+// checks folded into the record, Healthy how many of them were up and
+// Excluded how many do not count towards uptime (see models.StatusCounted).
+type Record struct {
+	ServiceID     string               `json:"id"`
+	Timestamp     time.Time            `json:"ts"`
+	Status        string               `json:"status"`
+	HealthStatus  string               `json:"health_status,omitempty"`
+	ExampleStatus string               `json:"example_status,omitempty"`
+	ResponseMs    int64                `json:"response_ms"`
+	Error         string               `json:"error,omitempty"`
+	Version       string               `json:"version,omitempty"`
+	RootCause     string               `json:"root_cause,omitempty"`
+	Timings       *models.PhaseTimings `json:"timings,omitempty"` // Raw checks only, dropped when downsampling
+	Samples       int                  `json:"samples,omitempty"`
+	Healthy       int                  `json:"healthy,omitempty"`
+	Excluded      int                  `json:"excluded,omitempty"` // Samples left out of uptime (maintenance, paused, unknown)
+}
+
+// SampleCount returns how many checks the record represents
+func (r Record) SampleCount() int {
+	if r.Samples > 0 {
+		return r.Samples
+	}
+	return 1
+}
+
+// HealthyCount returns how many of the represented checks were up (healthy or degraded)
+func (r Record) HealthyCount() int {
+	if r.Samples > 0 {
+		return r.Healthy
+	}
+	if models.StatusUp(r.Status) {
+		return 1
+	}
+	return 0
+}
+
+// UptimeSamples returns how many of the represented checks count towards uptime
+func (r Record) UptimeSamples() int {
+	if r.Samples > 0 {
+		return r.Samples - r.Excluded
+	}
+	if models.StatusCounted(r.Status) {
+		return 1
+	}
+	return 0
+}
+
+// Options configures retention and downsampling of the store
+type Options struct {
+	Retention      time.Duration // Records older than this are deleted
+	RawWindow      time.Duration // Records older than this are downsampled
+	DownsampleStep time.Duration // Bucket size used when downsampling
+}
+
+// DefaultOptions keeps raw checks for two days and 5-minute buckets for 30 days
+func DefaultOptions() Options {
+	return Options{
+		Retention:      30 * 24 * time.Hour,
+		RawWindow:      48 * time.Hour,
+		DownsampleStep: 5 * time.Minute,
+	}
+}
+
+// Store is an append-only on-disk store of check results.
+// Records are kept as JSON lines in one file per service per UTC day:
+// <dir>/<service id>/<YYYY-MM-DD>.jsonl
+type Store struct {
+	dir  string
+	opts Options
+	mu   sync.Mutex
+}
+
+// NewStore opens (or creates) a history store rooted at dir
+func NewStore(dir string, opts Options) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create history dir: %w", err)
+	}
+	if opts.DownsampleStep <= 0 {
+		opts.DownsampleStep = DefaultOptions().DownsampleStep
+	}
+	return &Store{dir: dir, opts: opts}, nil
+}
+
+// Retention returns how long records are kept, 0 for forever
+func (s *Store) Retention() time.Duration {
+	return s.opts.Retention
+}
+
+// Append writes a check result to the store
+func (s *Store) Append(rec Record) error {
+	if rec.ServiceID == "" {
+		return fmt.Errorf("record without service id")
+	}
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	rec.Timestamp = rec.Timestamp.UTC()
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svcDir := filepath.Join(s.dir, safeName(rec.ServiceID))
+	if err := os.MkdirAll(svcDir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(svcDir, rec.Timestamp.Format(dayLayout)+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Query returns the records of a service in [from, to], oldest first.
+// When step is positive the records are aggregated into buckets of that size.
+func (s *Store) Query(serviceID string, from, to time.Time, step time.Duration) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to = from.UTC(), to.UTC()
+	svcDir := filepath.Join(s.dir, safeName(serviceID))
+	records := []Record{}
+
+	// Only the day files that exist are read, however wide the range
+	files, err := os.ReadDir(svcDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, err
+	}
+	first := truncateDay(from)
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(name, ".jsonl"))
+		if err != nil || day.Before(first) || day.After(to) {
+			continue
+		}
+		dayRecords, err := readFile(filepath.Join(svcDir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, rec := range dayRecords {
+			if rec.Timestamp.Before(from) || rec.Timestamp.After(to) {
+				continue
+			}
+			records = append(records, rec)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	if step > 0 {
+		return Downsample(records, step), nil
+	}
+	return records, nil
+}
+
+// Downsample folds records into buckets of the given step.
+// Each bucket keeps the last status, error and version seen, the average
+// response time and the number of total and healthy samples.
+func Downsample(records []Record, step time.Duration) []Record {
+	if len(records) == 0 {
+		return records
+	}
+
+	out := []Record{}
+	var cur *Record
+	var totalMs int64
+
+	flush := func() {
+		if cur != nil {
+			cur.ResponseMs = totalMs / int64(cur.Samples)
+			out = append(out, *cur)
+		}
+	}
+
+	for _, rec := range records {
+		bucket := rec.Timestamp.Truncate(step)
+		if cur == nil || !cur.Timestamp.Equal(bucket) {
+			flush()
+			cur = &Record{ServiceID: rec.ServiceID, Timestamp: bucket}
+			totalMs = 0
+		}
+		n := rec.SampleCount()
+		cur.Samples += n
+		cur.Healthy += rec.HealthyCount()
+		cur.Excluded += n - rec.UptimeSamples()
+		totalMs += rec.ResponseMs * int64(n)
+		cur.Status = rec.Status
+		cur.HealthStatus = rec.HealthStatus
+		cur.ExampleStatus = rec.ExampleStatus
+		if rec.Error != "" {
+			cur.Error = rec.Error
+		}
+		if rec.Version != "" {
+			cur.Version = rec.Version
+		}
+	}
+	flush()
+	return out
+}
+
+// Compact applies retention and downsampling relative to now.
+// Day files older than the retention window are removed, and days entirely
+// older than the raw window are rewritten as downsampled buckets.
+func (s *Store) Compact(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now = now.UTC()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, svcEntry := range entries {
+		if !svcEntry.IsDir() {
+			continue
+		}
+		svcDir := filepath.Join(s.dir, svcEntry.Name())
+		files, err := os.ReadDir(svcDir)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			name := f.Name()
+			if !strings.HasSuffix(name, ".jsonl") {
+				continue
+			}
+			day, err := time.Parse(dayLayout, strings.TrimSuffix(name, ".jsonl"))
+			if err != nil {
+				continue
+			}
+			dayEnd := day.AddDate(0, 0, 1)
+			path := filepath.Join(svcDir, name)
+
+			if s.opts.Retention > 0 && now.Sub(dayEnd) > s.opts.Retention {
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.opts.RawWindow > 0 && now.Sub(dayEnd) > s.opts.RawWindow {
+				if err := s.downsampleFile(path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Maintain runs Compact periodically. It is meant to run in its own goroutine.
+func (s *Store) Maintain(interval time.Duration) {
+	if err := s.Compact(time.Now()); err != nil {
+		log.Printf("History compaction failed: %v", err)
+	}
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		if err := s.Compact(time.Now()); err != nil {
+			log.Printf("History compaction failed: %v", err)
+		}
+	}
+}
+
+func (s *Store) downsampleFile(path string) error {
+	records, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	compacted := Downsample(records, s.opts.DownsampleStep)
+	if len(compacted) == len(records) {
+		return nil // Already downsampled
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range compacted {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue // Skip torn writes
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// safeName keeps service IDs from escaping the store directory
+func safeName(id string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(id)
+}
@@ -0,0 +1,68 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestQueryReadsExistingDays(t *testing.T) {
+	s, err := NewStore(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, ts := range []time.Time{day.AddDate(0, 0, -40), day.AddDate(0, 0, -1), day, day.Add(time.Hour)} {
+		if err := s.Append(Record{ServiceID: "svc", Timestamp: ts, Status: models.StatusHealthy}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A range reaching back to year one only reads the files that exist
+	got, err := s.Query("svc", time.Time{}, day.Add(30*time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !got[0].Timestamp.Equal(day.AddDate(0, 0, -40)) || !got[2].Timestamp.Equal(day) {
+		t.Errorf("got %+v", got)
+	}
+
+	got, err = s.Query("svc", day.Add(-time.Minute), day.Add(2*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Samples != 1 {
+		t.Errorf("got %+v", got)
+	}
+
+	if got, err := s.Query("unknown", time.Time{}, day, 0); err != nil || len(got) != 0 {
+		t.Errorf("got %v, %v for an unknown service", got, err)
+	}
+}
+
+func TestCompactAppliesRetention(t *testing.T) {
+	s, err := NewStore(t.TempDir(), Options{Retention: 7 * 24 * time.Hour, RawWindow: 48 * time.Hour, DownsampleStep: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -10)
+	recent := now.AddDate(0, 0, -3)
+	for _, ts := range []time.Time{old, recent, recent.Add(time.Minute), now} {
+		if err := s.Append(Record{ServiceID: "svc", Timestamp: ts, Status: models.StatusUnhealthy}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Compact(now); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Query("svc", time.Time{}, now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Samples != 2 || got[0].Healthy != 0 || !got[1].Timestamp.Equal(now) {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,123 @@
+package incident
+
+import (
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Incident scopes
+const (
+	ScopeService  = "service"
+	ScopeCategory = "category" // Every checked service of the category is failing
+)
+
+// Incident states
+const (
+	StateOpen     = "open"
+	StateResolved = "resolved"
+)
+
+// Severities
+const (
+	SeverityMinor = "minor" // Degraded
+	SeverityMajor = "major" // Unhealthy or circuit open
+)
+
+// Resolutions
+const (
+	ResolvedRecovered = "recovered" // Back to healthy
+	ResolvedPaused    = "paused"    // Checks were paused by an operator
+	ResolvedRemoved   = "removed"   // The service left the registry
+)
+
+// Timeline entry kinds
+const (
+	EntryOpened       = "opened"
+	EntryCheck        = "check"  // A failing check with its root cause
+	EntryStatus       = "status" // A status change
+	EntryAcknowledged = "acknowledged"
+	EntryNote         = "note"
+	EntryResolved     = "resolved"
+)
+
+// Entry is one point on an incident's timeline
+type Entry struct {
+	At        time.Time `json:"at"`
+	Kind      string    `json:"kind"`
+	Service   string    `json:"service,omitempty"` // Set on category incidents
+	Status    string    `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	RootCause string    `json:"root_cause,omitempty"`
+	Summary   string    `json:"summary,omitempty"` // Diagnosis summary; the attempts are not kept
+	Author    string    `json:"author,omitempty"`
+	Text      string    `json:"text,omitempty"`
+}
+
+// Ack records who acknowledged an incident
+type Ack struct {
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+}
+
+// Incident is an outage of a service or a whole category, derived from the
+// checks: it opens when the target leaves healthy and resolves on recovery.
+type Incident struct {
+	ID           string          `json:"id"`
+	Scope        string          `json:"scope"`
+	Target       string          `json:"target"`             // Service ID or category name
+	Category     string          `json:"category,omitempty"` // Category of a service incident
+	Services     []string        `json:"services,omitempty"` // Failing services of a category incident
+	State        string          `json:"state"`
+	Severity     string          `json:"severity"`
+	Status       string          `json:"status"` // Latest status of the target
+	StartedAt    time.Time       `json:"started_at"`
+	ResolvedAt   *time.Time      `json:"resolved_at,omitempty"`
+	Resolution   string          `json:"resolution,omitempty"`
+	Duration     models.Duration `json:"duration"` // Until now while open
+	Acknowledged *Ack            `json:"acknowledged,omitempty"`
+	Checks       int             `json:"checks"`            // Failing checks seen, including ones dropped from the timeline
+	Dropped      int             `json:"dropped,omitempty"` // Check entries dropped from the timeline
+	Timeline     []Entry         `json:"timeline"`
+}
+
+// Open reports whether the incident is still ongoing
+func (i *Incident) Open() bool {
+	return i.State == StateOpen
+}
+
+// withDuration returns a copy of i with Duration computed at now
+func (i *Incident) withDuration(now time.Time) Incident {
+	c := *i
+	c.Services = append([]string(nil), i.Services...)
+	c.Timeline = append([]Entry(nil), i.Timeline...)
+	if i.Acknowledged != nil {
+		ack := *i.Acknowledged
+		c.Acknowledged = &ack
+	}
+	end := now
+	if i.ResolvedAt != nil {
+		end = *i.ResolvedAt
+	}
+	c.Duration = models.Duration(end.Sub(i.StartedAt))
+	return c
+}
+
+// Stats summarises the incidents of a period
+type Stats struct {
+	Open     int             `json:"open"`
+	Resolved int             `json:"resolved"`
+	MTTR     models.Duration `json:"mttr"` // Mean time to resolve
+	MTTA     models.Duration `json:"mtta"` // Mean time to acknowledge
+}
+
+// failing reports whether a status counts as an incident and its severity
+func failing(status string) (string, bool) {
+	switch status {
+	case models.StatusDegraded:
+		return SeverityMinor, true
+	case models.StatusUnhealthy, models.StatusCircuitOpen:
+		return SeverityMajor, true
+	}
+	return "", false
+}
@@ -0,0 +1,594 @@
+package incident
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// minCategoryServices is the number of checked services a category needs
+// before its failure is tracked as a category incident of its own
+const minCategoryServices = 2
+
+var (
+	// ErrNotFound is returned for unknown incident IDs
+	ErrNotFound = errors.New("incident not found")
+	// ErrAcknowledged is returned when acknowledging an incident twice
+	ErrAcknowledged = errors.New("incident already acknowledged")
+)
+
+// Options configures retention of the tracker
+type Options struct {
+	Retention time.Duration // Resolved incidents older than this are dropped
+	MaxChecks int           // Check entries kept on one timeline, older ones are dropped
+}
+
+// DefaultOptions keeps resolved incidents for 90 days and 50 checks per incident
+func DefaultOptions() Options {
+	return Options{
+		Retention: 90 * 24 * time.Hour,
+		MaxChecks: 50,
+	}
+}
+
+// Filter selects incidents in List
+type Filter struct {
+	State   string    // StateOpen or StateResolved, empty for both
+	Scope   string    // ScopeService or ScopeCategory, empty for both
+	Service string    // Incidents of the service, including category incidents it took part in
+	Since   time.Time // Incidents ongoing at or started after Since
+	Limit   int       // 0 = no limit
+}
+
+// member is the last state seen of a service
+type member struct {
+	category string
+	status   string
+}
+
+// Tracker derives incidents from check results and keeps them in a JSON file.
+// Openings, resolutions, acknowledgements and notes are written right away;
+// check entries are written by Run.
+type Tracker struct {
+	path string
+	opts Options
+
+	mu        sync.Mutex
+	incidents []*Incident          // Oldest first
+	open      map[string]*Incident // Keyed by scope:target
+	members   map[string]member    // Keyed by service ID
+	dirty     bool
+}
+
+func key(scope, target string) string {
+	return scope + ":" + target
+}
+
+// NewTracker loads (or creates) the tracker at path
+func NewTracker(path string, opts Options) (*Tracker, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create incident dir: %w", err)
+	}
+	if opts.MaxChecks <= 0 {
+		opts.MaxChecks = DefaultOptions().MaxChecks
+	}
+	t := &Tracker{
+		path:    path,
+		opts:    opts,
+		open:    make(map[string]*Incident),
+		members: make(map[string]member),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &t.incidents); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	for _, inc := range t.incidents {
+		if inc.Open() {
+			t.open[key(inc.Scope, inc.Target)] = inc
+		}
+	}
+	return t, nil
+}
+
+// Observe feeds the result of a check (or a pause) of svc to the tracker. It
+// opens, extends and resolves the incidents of the service and its category.
+// It is safe to call on a nil tracker.
+func (t *Tracker) Observe(svc models.Service, at time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, seen := t.members[svc.ID]
+	t.members[svc.ID] = member{category: svc.Category, status: svc.Status}
+
+	changed := t.observeService(svc, at)
+	if svc.Category != "" {
+		changed = t.observeCategory(svc.Category, &svc, at) || changed
+	}
+	if seen && prev.category != "" && prev.category != svc.Category {
+		changed = t.observeCategory(prev.category, nil, at) || changed
+	}
+	t.commit(changed)
+}
+
+// Forget resolves the incidents of a service that left the registry.
+// It is safe to call on a nil tracker.
+func (t *Tracker) Forget(id string, at time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m, seen := t.members[id]
+	delete(t.members, id)
+	changed := false
+	if inc := t.open[key(ScopeService, id)]; inc != nil {
+		t.resolve(inc, ResolvedRemoved, "", at)
+		changed = true
+	}
+	if seen && m.category != "" {
+		changed = t.observeCategory(m.category, nil, at) || changed
+	}
+	t.commit(changed)
+}
+
+// Reconcile resolves the open incidents whose service or category is not in
+// services, the registry as first seen after a restart: targets removed while
+// the dashboard was down would otherwise stay open. It is safe to call on a nil tracker.
+func (t *Tracker) Reconcile(services []models.Service, at time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := make(map[string]bool, len(services))
+	categories := make(map[string]bool)
+	for _, svc := range services {
+		ids[svc.ID] = true
+		if svc.Category != "" {
+			categories[svc.Category] = true
+		}
+	}
+	changed := false
+	for _, inc := range t.open {
+		if (inc.Scope == ScopeService && !ids[inc.Target]) || (inc.Scope == ScopeCategory && !categories[inc.Target]) {
+			t.resolve(inc, ResolvedRemoved, "", at)
+			changed = true
+		}
+	}
+	t.commit(changed)
+}
+
+// observeService updates the incident of one service. It reports whether an
+// incident was opened or resolved.
+func (t *Tracker) observeService(svc models.Service, at time.Time) bool {
+	k := key(ScopeService, svc.ID)
+	inc := t.open[k]
+	severity, bad := failing(svc.Status)
+
+	switch {
+	case bad && inc == nil:
+		inc = t.start(ScopeService, svc.ID, severity, svc.Status, at)
+		inc.Category = svc.Category
+		t.attach(inc, &svc, "")
+		return true
+	case bad:
+		if inc.Status != svc.Status {
+			inc.Timeline = append(inc.Timeline, Entry{At: at, Kind: EntryStatus, Status: svc.Status})
+			inc.Status = svc.Status
+		}
+		if severity == SeverityMajor {
+			inc.Severity = SeverityMajor
+		}
+		inc.Category = svc.Category
+		t.attach(inc, &svc, "")
+		t.dirty = true
+	case inc == nil:
+	case svc.Status == models.StatusHealthy:
+		t.resolve(inc, ResolvedRecovered, svc.Status, at)
+		return true
+	case svc.Status == models.StatusPaused:
+		t.resolve(inc, ResolvedPaused, svc.Status, at)
+		return true
+	case inc.Status != svc.Status:
+		// Maintenance or unknown: neither failing nor recovered yet
+		inc.Timeline = append(inc.Timeline, Entry{At: at, Kind: EntryStatus, Status: svc.Status})
+		inc.Status = svc.Status
+		t.dirty = true
+	}
+	return false
+}
+
+// observeCategory re-evaluates a category after one of its services changed.
+// svc is the service just checked, nil when it left the category. It reports
+// whether an incident was opened or resolved.
+func (t *Tracker) observeCategory(category string, svc *models.Service, at time.Time) bool {
+	var failed []string
+	checked, up, paused, total := 0, 0, 0, 0
+	major := false
+	for id, m := range t.members {
+		if m.category != category {
+			continue
+		}
+		total++
+		if m.status == models.StatusPaused {
+			paused++
+		}
+		if !models.StatusCounted(m.status) {
+			continue
+		}
+		checked++
+		if severity, bad := failing(m.status); bad {
+			failed = append(failed, id)
+			major = major || severity == SeverityMajor
+		} else if models.StatusUp(m.status) {
+			up++
+		}
+	}
+	sort.Strings(failed)
+	severity, status := SeverityMinor, models.StatusDegraded
+	if major {
+		severity, status = SeverityMajor, models.StatusUnhealthy
+	}
+
+	k := key(ScopeCategory, category)
+	inc := t.open[k]
+	switch {
+	case inc == nil && checked >= minCategoryServices && len(failed) == checked:
+		inc = t.start(ScopeCategory, category, severity, status, at)
+		inc.Services = failed
+		for _, id := range failed {
+			inc.Timeline = append(inc.Timeline, Entry{At: at, Kind: EntryStatus, Service: id, Status: t.members[id].status})
+		}
+		if svc != nil {
+			t.attach(inc, svc, svc.ID)
+		}
+		return true
+	case inc == nil:
+		return false
+	case up > 0:
+		t.resolve(inc, ResolvedRecovered, models.StatusHealthy, at)
+		return true
+	case total == 0:
+		t.resolve(inc, ResolvedRemoved, "", at)
+		return true
+	case paused == total:
+		t.resolve(inc, ResolvedPaused, models.StatusPaused, at)
+		return true
+	}
+
+	// Still failing (or only in maintenance / not checked yet)
+	if len(failed) > 0 {
+		inc.Services = mergeServices(inc.Services, failed)
+		if major {
+			inc.Severity = SeverityMajor
+		}
+		inc.Status = status
+	}
+	if svc != nil && svc.Category == category {
+		if _, bad := failing(svc.Status); bad {
+			t.attach(inc, svc, svc.ID)
+		} else if last := lastStatus(inc, svc.ID); last != svc.Status {
+			inc.Timeline = append(inc.Timeline, Entry{At: at, Kind: EntryStatus, Service: svc.ID, Status: svc.Status})
+		}
+		t.dirty = true
+	}
+	return false
+}
+
+// start opens a new incident
+func (t *Tracker) start(scope, target, severity, status string, at time.Time) *Incident {
+	inc := &Incident{
+		ID:        newID(),
+		Scope:     scope,
+		Target:    target,
+		State:     StateOpen,
+		Severity:  severity,
+		Status:    status,
+		StartedAt: at,
+		Timeline:  []Entry{{At: at, Kind: EntryOpened, Status: status}},
+	}
+	t.incidents = append(t.incidents, inc)
+	t.open[key(scope, target)] = inc
+	return inc
+}
+
+// resolve closes an open incident
+func (t *Tracker) resolve(inc *Incident, resolution, status string, at time.Time) {
+	inc.State = StateResolved
+	inc.Resolution = resolution
+	inc.ResolvedAt = &at
+	if status != "" {
+		inc.Status = status
+	}
+	inc.Timeline = append(inc.Timeline, Entry{At: at, Kind: EntryResolved, Status: status, Text: resolution})
+	delete(t.open, key(inc.Scope, inc.Target))
+}
+
+// attach adds a failing check of svc to the timeline, dropping the oldest
+// check entry once the timeline holds MaxChecks of them. service is set on
+// category incidents.
+func (t *Tracker) attach(inc *Incident, svc *models.Service, service string) {
+	at := svc.LastChecked
+	if at.IsZero() {
+		at = time.Now()
+	}
+	entry := Entry{
+		At:        at,
+		Kind:      EntryCheck,
+		Service:   service,
+		Status:    svc.Status,
+		Error:     svc.LastError,
+		RootCause: svc.RootCause,
+	}
+	if svc.Diagnosis != nil {
+		entry.Summary = svc.Diagnosis.Summary
+	}
+	inc.Timeline = append(inc.Timeline, entry)
+	inc.Checks++
+
+	checks, first := 0, -1
+	for i, e := range inc.Timeline {
+		if e.Kind == EntryCheck {
+			checks++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if checks > t.opts.MaxChecks {
+		inc.Timeline = append(inc.Timeline[:first:first], inc.Timeline[first+1:]...)
+		inc.Dropped++
+	}
+}
+
+// lastStatus returns the last status recorded on the timeline for a service
+func lastStatus(inc *Incident, service string) string {
+	for i := len(inc.Timeline) - 1; i >= 0; i-- {
+		if e := inc.Timeline[i]; e.Service == service && e.Status != "" {
+			return e.Status
+		}
+	}
+	return ""
+}
+
+func mergeServices(have, add []string) []string {
+	for _, id := range add {
+		i := sort.SearchStrings(have, id)
+		if i < len(have) && have[i] == id {
+			continue
+		}
+		have = append(have, "")
+		copy(have[i+1:], have[i:])
+		have[i] = id
+	}
+	return have
+}
+
+// List returns the incidents matching f, newest first
+func (t *Tracker) List(f Filter, now time.Time) []Incident {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := []Incident{}
+	for i := len(t.incidents) - 1; i >= 0; i-- {
+		inc := t.incidents[i]
+		if f.State != "" && inc.State != f.State {
+			continue
+		}
+		if f.Scope != "" && inc.Scope != f.Scope {
+			continue
+		}
+		if f.Service != "" && !inc.involves(f.Service) {
+			continue
+		}
+		if !f.Since.IsZero() && inc.ResolvedAt != nil && inc.ResolvedAt.Before(f.Since) {
+			continue
+		}
+		list = append(list, inc.withDuration(now))
+		if f.Limit > 0 && len(list) == f.Limit {
+			break
+		}
+	}
+	return list
+}
+
+// involves reports whether the incident concerns a service
+func (i *Incident) involves(id string) bool {
+	if i.Scope == ScopeService {
+		return i.Target == id
+	}
+	for _, s := range i.Services {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns one incident
+func (t *Tracker) Get(id string, now time.Time) (Incident, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inc := t.find(id)
+	if inc == nil {
+		return Incident{}, ErrNotFound
+	}
+	return inc.withDuration(now), nil
+}
+
+// Stats summarises the incidents ongoing at or started after since
+func (t *Tracker) Stats(since, now time.Time) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var st Stats
+	var resolveSum, ackSum time.Duration
+	acked := 0
+	for _, inc := range t.incidents {
+		if inc.ResolvedAt != nil && inc.ResolvedAt.Before(since) {
+			continue
+		}
+		if inc.Open() {
+			st.Open++
+		} else {
+			st.Resolved++
+			resolveSum += inc.ResolvedAt.Sub(inc.StartedAt)
+		}
+		if inc.Acknowledged != nil {
+			acked++
+			ackSum += inc.Acknowledged.At.Sub(inc.StartedAt)
+		}
+	}
+	if st.Resolved > 0 {
+		st.MTTR = models.Duration(resolveSum / time.Duration(st.Resolved))
+	}
+	if acked > 0 {
+		st.MTTA = models.Duration(ackSum / time.Duration(acked))
+	}
+	return st
+}
+
+// Acknowledge records that by is handling an open incident, with an optional note
+func (t *Tracker) Acknowledge(id, by, text string, now time.Time) (Incident, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inc := t.find(id)
+	if inc == nil {
+		return Incident{}, ErrNotFound
+	}
+	if inc.Acknowledged != nil {
+		return Incident{}, ErrAcknowledged
+	}
+	prev := len(inc.Timeline)
+	inc.Acknowledged = &Ack{By: by, At: now}
+	inc.Timeline = append(inc.Timeline, Entry{At: now, Kind: EntryAcknowledged, Author: by, Text: text})
+	if err := t.save(); err != nil {
+		inc.Acknowledged = nil
+		inc.Timeline = inc.Timeline[:prev]
+		return Incident{}, err
+	}
+	return inc.withDuration(now), nil
+}
+
+// AddNote appends a free-text note to the timeline of an incident
+func (t *Tracker) AddNote(id, author, text string, now time.Time) (Incident, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inc := t.find(id)
+	if inc == nil {
+		return Incident{}, ErrNotFound
+	}
+	prev := len(inc.Timeline)
+	inc.Timeline = append(inc.Timeline, Entry{At: now, Kind: EntryNote, Author: author, Text: text})
+	if err := t.save(); err != nil {
+		inc.Timeline = inc.Timeline[:prev]
+		return Incident{}, err
+	}
+	return inc.withDuration(now), nil
+}
+
+func (t *Tracker) find(id string) *Incident {
+	for _, inc := range t.incidents {
+		if inc.ID == id {
+			return inc
+		}
+	}
+	return nil
+}
+
+// Run writes pending check entries and drops expired incidents every
+// interval. It does not return.
+func (t *Tracker) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		if err := t.Flush(time.Now()); err != nil {
+			log.Printf("Failed to save incidents: %v", err)
+		}
+	}
+}
+
+// Flush drops expired incidents and writes the tracker if anything changed
+func (t *Tracker) Flush(now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.prune(now) {
+		t.dirty = true
+	}
+	if !t.dirty {
+		return nil
+	}
+	return t.save()
+}
+
+// commit writes the tracker after an incident opened or resolved
+func (t *Tracker) commit(changed bool) {
+	if !changed {
+		return
+	}
+	if err := t.save(); err != nil {
+		log.Printf("Failed to save incidents: %v", err)
+		t.dirty = true
+	}
+}
+
+// prune drops resolved incidents older than the retention
+func (t *Tracker) prune(now time.Time) bool {
+	if t.opts.Retention <= 0 {
+		return false
+	}
+	cutoff := now.Add(-t.opts.Retention)
+	kept := t.incidents[:0]
+	for _, inc := range t.incidents {
+		if inc.ResolvedAt != nil && inc.ResolvedAt.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, inc)
+	}
+	pruned := len(kept) != len(t.incidents)
+	t.incidents = kept
+	return pruned
+}
+
+// save writes the incidents atomically, unindented to keep the file small
+func (t *Tracker) save() error {
+	data, err := json.Marshal(t.incidents)
+	if err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return err
+	}
+	t.dirty = false
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,334 @@
+package incident
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestTracker(t *testing.T, opts Options) *Tracker {
+	t.Helper()
+	tr, err := NewTracker(filepath.Join(t.TempDir(), "incidents.json"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+// observe feeds one check per status to the tracker, a minute apart
+func observe(tr *Tracker, id, category string, statuses ...string) time.Time {
+	at := t0
+	for _, st := range statuses {
+		at = at.Add(time.Minute)
+		tr.Observe(models.Service{
+			ID:          id,
+			Category:    category,
+			Status:      st,
+			LastChecked: at,
+			LastError:   "HTTP 503",
+			RootCause:   models.RootCauseAppError,
+			Diagnosis:   &models.Diagnosis{RootCause: models.RootCauseAppError, Summary: "answered 503", Attempts: []models.Attempt{{URL: "http://svc:8080/health"}}},
+		}, at)
+	}
+	return at
+}
+
+func kinds(inc Incident) string {
+	k := make([]string, len(inc.Timeline))
+	for i, e := range inc.Timeline {
+		k[i] = e.Kind
+	}
+	return strings.Join(k, ",")
+}
+
+func TestServiceIncident(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []string
+		state      string // Empty when no incident opens
+		severity   string
+		status     string
+		resolution string
+		timeline   string
+	}{
+		{name: "healthy", statuses: []string{models.StatusHealthy, models.StatusHealthy}},
+		{name: "maintenance alone", statuses: []string{models.StatusMaintenance}},
+		{name: "degraded", statuses: []string{models.StatusDegraded}, state: StateOpen, severity: SeverityMinor, status: models.StatusDegraded, timeline: "opened,check"},
+		{name: "extended", statuses: []string{models.StatusDegraded, models.StatusDegraded}, state: StateOpen, severity: SeverityMinor, status: models.StatusDegraded, timeline: "opened,check,check"},
+		{name: "escalated", statuses: []string{models.StatusDegraded, models.StatusUnhealthy}, state: StateOpen, severity: SeverityMajor, status: models.StatusUnhealthy, timeline: "opened,check,status,check"},
+		{name: "severity kept", statuses: []string{models.StatusCircuitOpen, models.StatusDegraded}, state: StateOpen, severity: SeverityMajor, status: models.StatusDegraded, timeline: "opened,check,status,check"},
+		{name: "recovered", statuses: []string{models.StatusUnhealthy, models.StatusHealthy}, state: StateResolved, severity: SeverityMajor, status: models.StatusHealthy, resolution: ResolvedRecovered, timeline: "opened,check,resolved"},
+		{name: "paused", statuses: []string{models.StatusUnhealthy, models.StatusPaused}, state: StateResolved, severity: SeverityMajor, status: models.StatusPaused, resolution: ResolvedPaused, timeline: "opened,check,resolved"},
+		{name: "maintenance keeps it open", statuses: []string{models.StatusUnhealthy, models.StatusMaintenance, models.StatusMaintenance}, state: StateOpen, severity: SeverityMajor, status: models.StatusMaintenance, timeline: "opened,check,status"},
+		{name: "recovered after maintenance", statuses: []string{models.StatusUnhealthy, models.StatusMaintenance, models.StatusHealthy}, state: StateResolved, severity: SeverityMajor, status: models.StatusHealthy, resolution: ResolvedRecovered, timeline: "opened,check,status,resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTracker(t, DefaultOptions())
+			end := observe(tr, "svc", "", tt.statuses...)
+
+			list := tr.List(Filter{}, end)
+			if tt.state == "" {
+				if len(list) != 0 {
+					t.Fatalf("got %+v, want no incident", list)
+				}
+				return
+			}
+			if len(list) != 1 {
+				t.Fatalf("got %d incidents, want 1", len(list))
+			}
+			inc := list[0]
+			if inc.Scope != ScopeService || inc.Target != "svc" || inc.State != tt.state || inc.Severity != tt.severity || inc.Status != tt.status || inc.Resolution != tt.resolution {
+				t.Errorf("got %s %s %s/%s %s %s", inc.Scope, inc.Target, inc.State, inc.Resolution, inc.Severity, inc.Status)
+			}
+			if got := kinds(inc); got != tt.timeline {
+				t.Errorf("got timeline %s, want %s", got, tt.timeline)
+			}
+			if !inc.StartedAt.Equal(t0.Add(time.Minute)) {
+				t.Errorf("got start %s", inc.StartedAt)
+			}
+		})
+	}
+}
+
+func TestCheckEntriesKeepSummaryOnly(t *testing.T) {
+	tr := newTestTracker(t, Options{MaxChecks: 3})
+	end := observe(tr, "svc", "", models.StatusUnhealthy, models.StatusUnhealthy, models.StatusUnhealthy, models.StatusUnhealthy, models.StatusUnhealthy)
+
+	inc := tr.List(Filter{}, end)[0]
+	if inc.Checks != 5 || inc.Dropped != 2 || kinds(inc) != "opened,check,check,check" {
+		t.Fatalf("got %d checks, %d dropped, timeline %s", inc.Checks, inc.Dropped, kinds(inc))
+	}
+	// The oldest checks go first
+	if e := inc.Timeline[1]; !e.At.Equal(t0.Add(3*time.Minute)) || e.RootCause != models.RootCauseAppError || e.Summary != "answered 503" || e.Error != "HTTP 503" {
+		t.Errorf("got %+v", e)
+	}
+}
+
+func TestCategoryIncident(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        func(tr *Tracker)
+		state      string // Of the category incident, empty when none opens
+		services   string
+		resolution string
+	}{
+		{
+			name: "one of two failing",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusHealthy)
+			},
+		},
+		{
+			name: "single service category",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+			},
+		},
+		{
+			name: "all failing",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusDegraded)
+			},
+			state:    StateOpen,
+			services: "a,b",
+		},
+		{
+			name: "unchecked services do not count",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusDegraded)
+				observe(tr, "c", "tools", models.StatusMaintenance)
+			},
+			state:    StateOpen,
+			services: "a,b",
+		},
+		{
+			name: "one recovers",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusHealthy)
+			},
+			state:      StateResolved,
+			services:   "a,b",
+			resolution: ResolvedRecovered,
+		},
+		{
+			name: "all paused",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusUnhealthy)
+				observe(tr, "a", "tools", models.StatusPaused)
+				observe(tr, "b", "tools", models.StatusPaused)
+			},
+			state:      StateResolved,
+			services:   "a,b",
+			resolution: ResolvedPaused,
+		},
+		{
+			name: "all removed",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusUnhealthy)
+				tr.Forget("a", t0)
+				tr.Forget("b", t0)
+			},
+			state:      StateResolved,
+			services:   "a,b",
+			resolution: ResolvedRemoved,
+		},
+		{
+			name: "moving away leaves a failing category",
+			run: func(tr *Tracker) {
+				observe(tr, "a", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "tools", models.StatusHealthy)
+				observe(tr, "c", "tools", models.StatusUnhealthy)
+				observe(tr, "b", "media", models.StatusHealthy)
+			},
+			state:    StateOpen,
+			services: "a,c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTracker(t, DefaultOptions())
+			tt.run(tr)
+
+			list := tr.List(Filter{Scope: ScopeCategory}, t0.Add(time.Hour))
+			if tt.state == "" {
+				if len(list) != 0 {
+					t.Fatalf("got %+v, want no category incident", list)
+				}
+				return
+			}
+			if len(list) != 1 {
+				t.Fatalf("got %d category incidents, want 1", len(list))
+			}
+			inc := list[0]
+			if inc.Target != "tools" || inc.State != tt.state || inc.Resolution != tt.resolution || strings.Join(inc.Services, ",") != tt.services {
+				t.Errorf("got %s %s/%s %v", inc.Target, inc.State, inc.Resolution, inc.Services)
+			}
+		})
+	}
+}
+
+func TestForgetAndReconcile(t *testing.T) {
+	tr := newTestTracker(t, DefaultOptions())
+	observe(tr, "gone", "", models.StatusUnhealthy)
+	observe(tr, "kept", "", models.StatusUnhealthy)
+	observe(tr, "a", "old", models.StatusUnhealthy)
+	observe(tr, "b", "old", models.StatusUnhealthy)
+	tr.Forget("gone", t0)
+	if inc := tr.List(Filter{Service: "gone"}, t0)[0]; inc.State != StateResolved || inc.Resolution != ResolvedRemoved {
+		t.Fatalf("got %s/%s after Forget", inc.State, inc.Resolution)
+	}
+
+	// After a restart the registry no longer has category "old"
+	tr.Reconcile([]models.Service{{ID: "kept"}, {ID: "a"}, {ID: "b", Category: "new"}}, t0)
+	open := tr.List(Filter{State: StateOpen}, t0)
+	var targets []string
+	for _, inc := range open {
+		targets = append(targets, inc.Scope+":"+inc.Target)
+	}
+	if got := strings.Join(targets, ","); got != "service:b,service:a,service:kept" {
+		t.Errorf("got open %s", got)
+	}
+	if st := tr.Stats(t0.Add(-time.Hour), t0); st.Open != 3 || st.Resolved != 2 {
+		t.Errorf("got %+v", st)
+	}
+
+	var nilTracker *Tracker
+	nilTracker.Reconcile(nil, t0)
+	nilTracker.Observe(models.Service{ID: "x"}, t0)
+	nilTracker.Forget("x", t0)
+}
+
+func TestAcknowledgeAndNotes(t *testing.T) {
+	tr := newTestTracker(t, DefaultOptions())
+	end := observe(tr, "svc", "", models.StatusUnhealthy)
+	id := tr.List(Filter{}, end)[0].ID
+
+	if _, err := tr.Acknowledge("nope", "alice", "", end); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+	inc, err := tr.Acknowledge(id, "alice", "looking", end.Add(2*time.Minute))
+	if err != nil || inc.Acknowledged == nil || inc.Acknowledged.By != "alice" {
+		t.Fatalf("got %+v, %v", inc.Acknowledged, err)
+	}
+	if _, err := tr.Acknowledge(id, "bob", "", end); !errors.Is(err, ErrAcknowledged) {
+		t.Errorf("got %v, want ErrAcknowledged", err)
+	}
+	if _, err := tr.AddNote("nope", "bob", "x", end); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+	inc, err = tr.AddNote(id, "bob", "restarted", end.Add(3*time.Minute))
+	if err != nil || kinds(inc) != "opened,check,acknowledged,note" || inc.Timeline[3].Author != "bob" || inc.Timeline[3].Text != "restarted" {
+		t.Fatalf("got %s, %v", kinds(inc), err)
+	}
+	if st := tr.Stats(t0, end); st.MTTA.D() != 2*time.Minute {
+		t.Errorf("got mtta %s", st.MTTA.D())
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+	tr, err := NewTracker(path, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	observe(tr, "old", "", models.StatusUnhealthy, models.StatusHealthy)
+	end := observe(tr, "svc", "", models.StatusUnhealthy, models.StatusUnhealthy)
+	id := tr.List(Filter{State: StateOpen}, end)[0].ID
+	if _, err := tr.AddNote(id, "bob", "on it", end); err != nil {
+		t.Fatal(err)
+	}
+	observe(tr, "svc", "", models.StatusUnhealthy, models.StatusUnhealthy, models.StatusUnhealthy)
+	if err := tr.Flush(end); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewTracker(path, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := tr.List(Filter{}, end), reloaded.List(Filter{}, end)
+	if len(got) != 2 {
+		t.Fatalf("got %d incidents, want 2", len(got))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].State != want[i].State || got[i].Checks != want[i].Checks || kinds(got[i]) != kinds(want[i]) {
+			t.Errorf("got %s %s %d %s, want %s %s %d %s", got[i].ID, got[i].State, got[i].Checks, kinds(got[i]), want[i].ID, want[i].State, want[i].Checks, kinds(want[i]))
+		}
+	}
+	if e := got[0].Timeline[1]; e.Summary != "answered 503" {
+		t.Errorf("got check entry %+v", e)
+	}
+
+	// The open incident is picked up again, not opened twice
+	observe(reloaded, "svc", "", models.StatusHealthy)
+	open := reloaded.List(Filter{State: StateOpen}, end)
+	if all := reloaded.List(Filter{}, end); len(open) != 0 || len(all) != 2 || all[0].Resolution != ResolvedRecovered {
+		t.Errorf("got %d open of %d", len(open), len(all))
+	}
+
+	// Resolved incidents past the retention are dropped
+	short, err := NewTracker(path, Options{Retention: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := short.Flush(t0.Add(48 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if all := short.List(Filter{}, t0); len(all) != 0 {
+		t.Errorf("got %d incidents after retention", len(all))
+	}
+}
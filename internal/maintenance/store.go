@@ -0,0 +1,404 @@
+package maintenance
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Kinds of entries
+const (
+	KindWindow  = "window"  // Scheduled: one-off (start/end) or recurring (cron/duration)
+	KindSilence = "silence" // Ad-hoc: starts when created and lasts for duration
+)
+
+const (
+	// maxDuration bounds recurring windows and silences
+	maxDuration = 7 * 24 * time.Hour
+	// keepEnded is how long finished one-off windows and silences stay listed
+	keepEnded = 7 * 24 * time.Hour
+	// nextLookahead bounds the search for the next start of a recurring window
+	nextLookahead = 366 * 24 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned for unknown window IDs
+	ErrNotFound = errors.New("maintenance window not found")
+	// ErrInvalid wraps validation failures of new windows
+	ErrInvalid = errors.New("invalid maintenance window")
+)
+
+// Window is a maintenance window or silence. It covers every service listed in
+// Services ("*" for all), in one of Categories or carrying one of Tags.
+// Recurring windows start whenever Cron fires and last Duration; Start and End
+// then bound the period in which they recur.
+type Window struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	Reason     string          `json:"reason,omitempty"`
+	Services   []string        `json:"services,omitempty"`
+	Categories []string        `json:"categories,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	Start      time.Time       `json:"start,omitempty"`
+	End        time.Time       `json:"end,omitempty"`
+	Cron       string          `json:"cron,omitempty"`
+	Duration   models.Duration `json:"duration,omitempty"`
+	Timezone   string          `json:"timezone,omitempty"` // IANA zone for Cron, default UTC
+	CreatedBy  string          `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+
+	schedule *Schedule
+	loc      *time.Location
+	// LastStart scans minute by minute, so its result is kept for the minute startAt
+	startAt   time.Time
+	lastStart time.Time
+}
+
+// Status is a window together with its state at a point in time
+type Status struct {
+	Window
+	Active    bool       `json:"active"`
+	Until     *time.Time `json:"until,omitempty"`      // End of the current occurrence
+	NextStart *time.Time `json:"next_start,omitempty"` // Next occurrence when not active
+}
+
+// prepare validates w and compiles its cron expression
+func (w *Window) prepare() error {
+	if len(w.Services) == 0 && len(w.Categories) == 0 && len(w.Tags) == 0 {
+		return fmt.Errorf("%w: set services, categories or tags (services [\"*\"] covers all)", ErrInvalid)
+	}
+	if w.Duration.D() < 0 || w.Duration.D() > maxDuration {
+		return fmt.Errorf("%w: duration must be between 0 and %s", ErrInvalid, maxDuration)
+	}
+
+	w.startAt = time.Time{}
+	if w.Cron == "" {
+		w.schedule, w.loc = nil, nil
+		if w.Start.IsZero() || w.End.IsZero() {
+			return fmt.Errorf("%w: one-off windows need start and end (or duration)", ErrInvalid)
+		}
+		if !w.End.After(w.Start) {
+			return fmt.Errorf("%w: end must be after start", ErrInvalid)
+		}
+		return nil
+	}
+
+	if w.Kind == KindSilence {
+		return fmt.Errorf("%w: silences cannot recur", ErrInvalid)
+	}
+	if w.Duration <= 0 {
+		return fmt.Errorf("%w: recurring windows need a duration", ErrInvalid)
+	}
+	schedule, err := ParseCron(w.Cron)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	loc := time.UTC
+	if w.Timezone != "" {
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+	w.schedule, w.loc = schedule, loc
+	return nil
+}
+
+// occurrence returns the occurrence of w covering now, if any
+func (w *Window) occurrence(now time.Time) (start, end time.Time, ok bool) {
+	if !w.Start.IsZero() && now.Before(w.Start) {
+		return time.Time{}, time.Time{}, false
+	}
+	if !w.End.IsZero() && !now.Before(w.End) {
+		return time.Time{}, time.Time{}, false
+	}
+	if w.schedule == nil {
+		return w.Start, w.End, true
+	}
+	start = w.lastStartAt(now)
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, false
+	}
+	end = start.Add(w.Duration.D())
+	if !w.End.IsZero() && end.After(w.End) {
+		end = w.End
+	}
+	if !now.Before(end) {
+		return time.Time{}, time.Time{}, false // LastStart only has minute precision
+	}
+	return start, end, true
+}
+
+// lastStartAt returns the cron start covering now, computed once per minute
+func (w *Window) lastStartAt(now time.Time) time.Time {
+	minute := now.Truncate(time.Minute)
+	if w.startAt.IsZero() || !w.startAt.Equal(minute) {
+		w.lastStart = w.schedule.LastStart(now.In(w.loc), w.Duration.D())
+		w.startAt = minute
+	}
+	return w.lastStart
+}
+
+// next returns the start of the next occurrence after now, or the zero time
+func (w *Window) next(now time.Time) time.Time {
+	if w.schedule == nil {
+		if now.Before(w.Start) {
+			return w.Start
+		}
+		return time.Time{}
+	}
+	from := now
+	if from.Before(w.Start) {
+		from = w.Start.Add(-time.Minute)
+	}
+	t := w.schedule.Next(from.In(w.loc), nextLookahead)
+	if t.IsZero() || (!w.End.IsZero() && !t.Before(w.End)) {
+		return time.Time{}
+	}
+	return t
+}
+
+// during reports whether the window may be active at some point in [from, to)
+func (w *Window) during(from, to time.Time) bool {
+	if w.schedule == nil {
+		return w.Start.Before(to) && w.End.After(from)
+	}
+	_, _, atStart := w.occurrence(from)
+	_, _, atEnd := w.occurrence(to.Add(-time.Nanosecond))
+	return atStart || atEnd
+}
+
+// ended reports whether a one-off window or silence is over
+func (w *Window) ended(now time.Time) bool {
+	return !w.End.IsZero() && !now.Before(w.End)
+}
+
+// Covers reports whether the window selects svc
+func (w *Window) Covers(svc *models.Service) bool {
+	for _, id := range w.Services {
+		if id == "*" || id == svc.ID {
+			return true
+		}
+	}
+	for _, c := range w.Categories {
+		if c == svc.Category {
+			return true
+		}
+	}
+	for _, t := range w.Tags {
+		for _, st := range svc.Tags {
+			if t == st {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Store keeps maintenance windows and silences in a JSON file.
+// Windows are only used with mu held, which also guards their cached cron starts.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	windows []*Window
+	// Windows active during the minute activeAt, recomputed once per minute
+	activeAt time.Time
+	active   []*Window
+}
+
+// NewStore loads (or creates) the store at path
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create maintenance dir: %w", err)
+	}
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.windows); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	for _, w := range s.windows {
+		if err := w.prepare(); err != nil {
+			return nil, fmt.Errorf("window %s: %w", w.ID, err)
+		}
+	}
+	return s, nil
+}
+
+// List returns every window with its state at now, active ones first
+func (s *Store) List(now time.Time) []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+
+	list := make([]Status, 0, len(s.windows))
+	for _, w := range s.windows {
+		list = append(list, status(w, now))
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Active != list[j].Active {
+			return list[i].Active
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Get returns one window with its state at now
+func (s *Store) Get(id string, now time.Time) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.windows {
+		if w.ID == id {
+			return status(w, now), nil
+		}
+	}
+	return Status{}, ErrNotFound
+}
+
+func status(w *Window, now time.Time) Status {
+	st := Status{Window: *w}
+	if _, end, ok := w.occurrence(now); ok {
+		st.Active = true
+		st.Until = &end
+	} else if next := w.next(now); !next.IsZero() {
+		st.NextStart = &next
+	}
+	return st
+}
+
+// Add validates and stores a new window. Silences start at now and last
+// Duration unless End is given.
+func (s *Store) Add(w Window, now time.Time) (Window, error) {
+	if w.Kind == "" {
+		w.Kind = KindWindow
+	}
+	if w.Kind != KindWindow && w.Kind != KindSilence {
+		return Window{}, fmt.Errorf("%w: kind must be %q or %q", ErrInvalid, KindWindow, KindSilence)
+	}
+	if w.Kind == KindSilence || (w.Cron == "" && w.Start.IsZero()) {
+		w.Start = now
+	}
+	if w.Cron == "" && w.End.IsZero() && w.Duration > 0 {
+		w.End = w.Start.Add(w.Duration.D())
+	}
+	if err := w.prepare(); err != nil {
+		return Window{}, err
+	}
+	w.ID = newID()
+	w.CreatedAt = now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	s.windows = append(s.windows, &w)
+	s.activeAt = time.Time{}
+	if err := s.save(); err != nil {
+		s.windows = s.windows[:len(s.windows)-1]
+		return Window{}, err
+	}
+	return w, nil
+}
+
+// Delete removes a window, ending it immediately
+func (s *Store) Delete(id string) (Window, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.windows {
+		if w.ID != id {
+			continue
+		}
+		prev := s.windows
+		s.windows = append(append([]*Window{}, s.windows[:i]...), s.windows[i+1:]...)
+		s.activeAt = time.Time{}
+		if err := s.save(); err != nil {
+			s.windows = prev
+			return Window{}, err
+		}
+		return *w, nil
+	}
+	return Window{}, ErrNotFound
+}
+
+// Match returns the first active window covering svc, or nil.
+// It is safe to call on a nil store.
+func (s *Store) Match(svc *models.Service, now time.Time) *Window {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	minute := now.Truncate(time.Minute)
+	if !s.activeAt.Equal(minute) {
+		s.prune(now)
+		s.active = s.active[:0]
+		for _, w := range s.windows {
+			if w.during(minute, minute.Add(time.Minute)) {
+				s.active = append(s.active, w)
+			}
+		}
+		s.activeAt = minute
+	}
+	for _, w := range s.active {
+		if _, _, ok := w.occurrence(now); ok && w.Covers(svc) {
+			return w
+		}
+	}
+	return nil
+}
+
+// prune drops one-off windows and silences that ended long ago and saves
+// the store when it did. It runs on every write, listing and once a minute
+// from Match.
+func (s *Store) prune(now time.Time) {
+	kept := make([]*Window, 0, len(s.windows))
+	for _, w := range s.windows {
+		if w.schedule == nil && w.ended(now.Add(-keepEnded)) {
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if len(kept) == len(s.windows) {
+		return
+	}
+	s.windows = kept
+	s.activeAt = time.Time{}
+	if err := s.save(); err != nil {
+		log.Printf("Failed to save pruned maintenance windows: %v", err)
+	}
+}
+
+// save writes the windows atomically
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.windows, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,87 @@
+package maintenance
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestMatchRecurringWindow(t *testing.T) {
+	s, _ := newTestStore(t)
+	day := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	w, err := s.Add(Window{Services: []string{"*"}, Cron: "0 3 * * *", Duration: models.Duration(90 * time.Second)}, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := &models.Service{ID: "svc"}
+
+	tests := []struct {
+		at     time.Time
+		active bool
+	}{
+		{day.Add(2*time.Hour + 59*time.Minute), false},
+		{day.Add(3 * time.Hour), true},
+		{day.Add(3*time.Hour + 80*time.Second), true},
+		{day.Add(3*time.Hour + 90*time.Second), false},
+		{day.Add(27 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		if got := s.Match(svc, tt.at) != nil; got != tt.active {
+			t.Errorf("at %s: got active %v, want %v", tt.at.Format(time.Kitchen), got, tt.active)
+		}
+	}
+
+	// The cron start is computed once per minute and reused within it
+	stored := s.windows[0]
+	if !stored.startAt.Equal(day.Add(27*time.Hour)) || !stored.lastStart.Equal(day.Add(27*time.Hour)) {
+		t.Errorf("got cached start %s for minute %s", stored.lastStart, stored.startAt)
+	}
+	if st, _ := s.Get(w.ID, day.Add(3*time.Hour+30*time.Second)); !st.Active || !st.Until.Equal(day.Add(3*time.Hour+90*time.Second)) {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestExpiredEntriesArePrunedOnRead(t *testing.T) {
+	s, path := newTestStore(t)
+	now := time.Date(2026, 11, 2, 12, 0, 0, 0, time.UTC)
+	silence, err := s.Add(Window{Kind: KindSilence, Services: []string{"svc"}, Duration: models.Duration(time.Hour)}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(Window{Services: []string{"*"}, Cron: "0 3 * * *", Duration: models.Duration(time.Hour)}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.Match(&models.Service{ID: "svc"}, now.Add(time.Minute)); got == nil || got.ID != silence.ID {
+		t.Fatalf("got %v, want the silence", got)
+	}
+	if list := s.List(now.Add(keepEnded)); len(list) != 2 {
+		t.Fatalf("got %d entries, want the ended silence still listed", len(list))
+	}
+
+	// Reading after the keep period drops the silence, in memory and on disk
+	later := now.Add(time.Hour + keepEnded + time.Minute)
+	s.Match(&models.Service{ID: "svc"}, later)
+	if len(s.windows) != 1 || s.windows[0].Cron == "" {
+		t.Fatalf("got %d windows after pruning, want the recurring one", len(s.windows))
+	}
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := reloaded.List(later); len(list) != 1 {
+		t.Errorf("got %d entries after reload, want 1", len(list))
+	}
+}
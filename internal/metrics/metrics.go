@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are histogram upper bounds in seconds for HTTP checks
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Histogram is a cumulative Prometheus-style histogram
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewHistogram creates a histogram with the given upper bounds
+func NewHistogram(buckets []float64) *Histogram {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Histogram{buckets: b, counts: make([]uint64, len(b))}
+}
+
+// Observe adds one value
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, ub := range h.buckets {
+		if v <= ub {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// Snapshot returns cumulative bucket counts, total count and sum
+func (h *Histogram) Snapshot() (buckets []float64, counts []uint64, count uint64, sum float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.buckets, append([]uint64(nil), h.counts...), h.count, h.sum
+}
+
+// Labels is an ordered list of label name/value pairs
+type Labels [][2]string
+
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	parts := make([]string, len(l))
+	for i, kv := range l {
+		parts[i] = kv[0] + `="` + escape(kv[1]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Writer renders the Prometheus text exposition format
+type Writer struct {
+	w       io.Writer
+	written map[string]bool
+}
+
+// NewWriter wraps an io.Writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, written: make(map[string]bool)}
+}
+
+// Header writes HELP and TYPE lines once per metric family
+func (w *Writer) Header(name, typ, help string) {
+	if w.written[name] {
+		return
+	}
+	w.written[name] = true
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// Sample writes one sample line
+func (w *Writer) Sample(name string, labels Labels, value float64) {
+	fmt.Fprintf(w.w, "%s%s %s\n", name, labels, formatFloat(value))
+}
+
+// Gauge writes the header and a single sample
+func (w *Writer) Gauge(name, help string, labels Labels, value float64) {
+	w.Header(name, "gauge", help)
+	w.Sample(name, labels, value)
+}
+
+// Histogram writes the bucket, sum and count series of h
+func (w *Writer) Histogram(name, help string, labels Labels, h *Histogram) {
+	w.Header(name, "histogram", help)
+	buckets, counts, count, sum := h.Snapshot()
+	for i, ub := range buckets {
+		w.Sample(name+"_bucket", append(labels[:len(labels):len(labels)], [2]string{"le", formatFloat(ub)}), float64(counts[i]))
+	}
+	w.Sample(name+"_bucket", append(labels[:len(labels):len(labels)], [2]string{"le", "+Inf"}), float64(count))
+	w.Sample(name+"_sum", labels, sum)
+	w.Sample(name+"_count", labels, float64(count))
+}
+
+// Bool converts a flag to a gauge value
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelEscaper applies the exposition format escaping of label values:
+// backslash, double quote and line feed, nothing else
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(v string) string {
+	return labelEscaper.Replace(v)
+}
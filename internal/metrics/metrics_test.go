@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLabelsString(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels Labels
+		want   string
+	}{
+		{name: "empty", want: ""},
+		{name: "plain", labels: Labels{{"id", "svc"}, {"category", "tools"}}, want: `{id="svc",category="tools"}`},
+		{name: "quote and backslash", labels: Labels{{"id", `a"b\c`}}, want: `{id="a\"b\\c"}`},
+		{name: "newline", labels: Labels{{"id", "a\nb"}}, want: `{id="a\nb"}`},
+		{name: "left as is", labels: Labels{{"id", "tab\there é\x01"}}, want: "{id=\"tab\there é\x01\"}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.labels.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]float64{1, 0.1})
+	for _, v := range []float64{0.05, 0.5, 2} {
+		h.Observe(v)
+	}
+	var b strings.Builder
+	NewWriter(&b).Histogram("latency_seconds", "Latency.", Labels{{"id", "svc"}}, h)
+	want := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{id="svc",le="0.1"} 1
+latency_seconds_bucket{id="svc",le="1"} 2
+latency_seconds_bucket{id="svc",le="+Inf"} 3
+latency_seconds_sum{id="svc"} 2.55
+latency_seconds_count{id="svc"} 3
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
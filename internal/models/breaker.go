@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Checks run normally
+	BreakerOpen     = "open"      // Checks are skipped until OpenUntil
+	BreakerHalfOpen = "half_open" // One probe decides between closed and open
+)
+
+// BreakerState is the circuit breaker of one service
+type BreakerState struct {
+	State     string    `json:"state"`
+	Failures  int       `json:"failures"`             // Consecutive failing checks
+	Trips     int       `json:"trips"`                // Consecutive openings without a successful probe
+	OpenedAt  time.Time `json:"opened_at,omitempty"`  // When the breaker last opened
+	OpenUntil time.Time `json:"open_until,omitempty"` // When the next half-open probe is allowed
+	Reason    string    `json:"reason,omitempty"`     // Error of the check that opened it
+}
+
+// Closed reports whether checks run normally. The zero value is closed.
+func (b BreakerState) Closed() bool {
+	return b.State == "" || b.State == BreakerClosed
+}
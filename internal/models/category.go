@@ -0,0 +1,49 @@
+package models
+
+// CategoryMeta is optional descriptive data for a category (config/categories.json)
+type CategoryMeta struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	PortMin     int    `json:"port_min,omitempty"`
+	PortMax     int    `json:"port_max,omitempty"`
+}
+
+// InPortRange reports whether port falls in the category's range (true when no range is set)
+func (c CategoryMeta) InPortRange(port int) bool {
+	if c.PortMin == 0 && c.PortMax == 0 {
+		return true
+	}
+	return port >= c.PortMin && port <= c.PortMax
+}
+
+// SetCategories replaces the category metadata
+func (r *Registry) SetCategories(meta []CategoryMeta) {
+	m := make(map[string]CategoryMeta, len(meta))
+	for _, c := range meta {
+		m[c.Name] = c
+	}
+	r.mu.Lock()
+	r.categories = m
+	r.mu.Unlock()
+}
+
+// CategoryMeta returns the metadata of a category, if configured
+func (r *Registry) CategoryMeta(name string) (CategoryMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.categories[name]
+	return c, ok
+}
+
+// Categories returns a copy of the category metadata by name
+func (r *Registry) Categories() map[string]CategoryMeta {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m := make(map[string]CategoryMeta, len(r.categories))
+	for name, c := range r.categories {
+		m[name] = c
+	}
+	return m
+}
@@ -0,0 +1,114 @@
+package models
+
+// CheckSpec declares how a service's health is checked.
+// Every field is optional; unset fields fall back to the default behaviour:
+// GET /health, expect HTTP 200 and a JSON "status" of "healthy" or "ok"
+// (non-JSON 200 responses are accepted), fall back to the public HealthURL
+// when the internal request fails,
+// then probe the ExampleURL and reject HTML responses.
+type CheckSpec struct {
+	Method         string            `json:"method,omitempty"`
+	Path           string            `json:"path,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	ExpectedStatus []int             `json:"expected_status,omitempty"`
+	StatusField    string            `json:"status_field,omitempty"`    // JSON path of the status value, "" after resolving when skipped
+	SkipStatus     bool              `json:"skip_status,omitempty"`     // Do not check a status value at all
+	StatusValues   []string          `json:"status_values,omitempty"`   // Accepted values of StatusField
+	DegradedValues []string          `json:"degraded_values,omitempty"` // Values of StatusField that mean degraded rather than down
+	VersionField   string            `json:"version_field,omitempty"`   // JSON path of the version value
+	RequireJSON    bool              `json:"require_json,omitempty"`    // Fail non-JSON responses instead of accepting them
+	Assertions     []Assertion       `json:"assertions,omitempty"`
+	MaxResponseMs  int64             `json:"max_response_ms,omitempty"` // Fail responses slower than this
+	DegradedMs     int64             `json:"degraded_ms,omitempty"`     // Passing responses slower than this are degraded, 0 (default) disables
+	PublicFallback *bool             `json:"public_fallback,omitempty"` // Try HealthURL when internal checks fail (default true)
+	Example        *ExampleSpec      `json:"example,omitempty"`
+}
+
+// ExampleSpec declares how the ExampleURL is probed
+type ExampleSpec struct {
+	Method         string            `json:"method,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	ExpectedStatus []int             `json:"expected_status,omitempty"` // Default: any 2xx/3xx
+	AllowHTML      bool              `json:"allow_html,omitempty"`
+	Assertions     []Assertion       `json:"assertions,omitempty"`
+	MaxResponseMs  int64             `json:"max_response_ms,omitempty"`
+}
+
+// Assertion checks a value inside a JSON response.
+// Path uses dots for object keys and numbers for array indices ("data.items.0.id").
+// Op is one of: exists, not_exists, equals, not_equals, contains.
+type Assertion struct {
+	Path  string      `json:"path"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Attempt records one request made during a check
+type Attempt struct {
+	Kind        string `json:"kind"` // One of the Attempt* kinds
+	URL         string `json:"url"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	DNS         string `json:"dns,omitempty"` // Resolved addresses, or the lookup error
+	Reused      bool   `json:"reused,omitempty"`
+	DNSMs       int64  `json:"dns_ms,omitempty"`
+	ConnectMs   int64  `json:"connect_ms,omitempty"`
+	TLSMs       int64  `json:"tls_ms,omitempty"`
+	TTFBMs      int64  `json:"ttfb_ms,omitempty"` // Request start to first response byte
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ErrorClass  string `json:"error_class,omitempty"` // One of the ErrClass* values, "" on success
+	ElapsedMs   int64  `json:"elapsed_ms"`
+}
+
+// Attempt kinds
+const (
+	AttemptInternalHealth  = "internal_health"
+	AttemptPublicHealth    = "public_health"
+	AttemptPublicExample   = "public_example"
+	AttemptInternalExample = "internal_example"
+)
+
+// Attempt error classes
+const (
+	ErrClassDNS      = "dns"
+	ErrClassRefused  = "refused"
+	ErrClassTimeout  = "timeout"
+	ErrClassTLS      = "tls"
+	ErrClassReset    = "reset"
+	ErrClassHTTP4xx  = "http_4xx"
+	ErrClassHTTP5xx  = "http_5xx"
+	ErrClassRejected = "rejected" // Response arrived but failed the check spec
+	ErrClassOther    = "other"
+)
+
+// Failed reports whether the attempt did not pass
+func (a Attempt) Failed() bool {
+	return a.ErrorClass != ""
+}
+
+// Timings returns the phase breakdown of the attempt
+func (a Attempt) Timings() PhaseTimings {
+	return PhaseTimings{
+		DNSMs:     a.DNSMs,
+		ConnectMs: a.ConnectMs,
+		TLSMs:     a.TLSMs,
+		TTFBMs:    a.TTFBMs,
+		TotalMs:   a.ElapsedMs,
+		Reused:    a.Reused,
+	}
+}
+
+// PhaseTimings is the latency breakdown of a single request.
+// Phases are zero when skipped, e.g. DNS and connect on a reused connection.
+type PhaseTimings struct {
+	DNSMs     int64 `json:"dns_ms"`
+	ConnectMs int64 `json:"connect_ms"`
+	TLSMs     int64 `json:"tls_ms"`
+	TTFBMs    int64 `json:"ttfb_ms"`
+	TotalMs   int64 `json:"total_ms"`
+	Reused    bool  `json:"reused,omitempty"` // Kept-alive connection, so DNS, connect and TLS did not run
+}
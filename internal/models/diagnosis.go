@@ -0,0 +1,23 @@
+package models
+
+// Root causes assigned by the check classifier
+const (
+	RootCauseNone          = "none"
+	RootCauseDNS           = "dns"            // Public hostname does not resolve
+	RootCauseContainerDown = "container_down" // Container not resolvable or not listening
+	RootCauseNetwork       = "network"        // Container not attached to the dashboard's network
+	RootCauseProxy         = "proxy"          // Internal is fine, nginx/proxy in front of it is not
+	RootCauseTLS           = "tls"            // Certificate or handshake failure on the public URL
+	RootCauseAppError      = "app_error"      // The service answered with an error or a failing health body
+	RootCauseWrongPort     = "wrong_port"     // The service only answers on a port other than the configured one
+	RootCauseTimeout       = "timeout"        // Connections were accepted but nothing answered in time
+	RootCauseUpstream      = "upstream"       // A declared dependency is failing, see Service.ImpactedBy
+	RootCauseUnknown       = "unknown"
+)
+
+// Diagnosis is the structured outcome of one check
+type Diagnosis struct {
+	RootCause string    `json:"root_cause"`
+	Summary   string    `json:"summary"`
+	Attempts  []Attempt `json:"attempts"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// DNS record states of a public hostname
+const (
+	DNSOK      = "ok"
+	DNSMissing = "missing" // NXDOMAIN or no A/AAAA records
+	DNSDrift   = "drift"   // Resolves, but not to the expected ingress (or resolvers disagree)
+	DNSError   = "error"   // No resolver gave an answer
+)
+
+// DNSAnswer is what one resolver returned for a hostname
+type DNSAnswer struct {
+	Resolver string   `json:"resolver"` // "system" for the host's resolver
+	CNAME    string   `json:"cname,omitempty"`
+	A        []string `json:"a,omitempty"`
+	AAAA     []string `json:"aaaa,omitempty"`
+	NotFound bool     `json:"not_found,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// DNSStatus is the result of resolving one public hostname
+type DNSStatus struct {
+	Host       string      `json:"host"`
+	Status     string      `json:"status"`
+	Expected   []string    `json:"expected,omitempty"` // Expected ingress addresses
+	Unexpected []string    `json:"unexpected,omitempty"`
+	Answers    []DNSAnswer `json:"answers"`
+	Reason     string      `json:"reason,omitempty"`
+	CheckedAt  time.Time   `json:"checked_at"`
+}
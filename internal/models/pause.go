@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Pause scopes
+const (
+	PauseService  = "service"
+	PauseCategory = "category"
+)
+
+// Pause records that checks of a service, or of every service in a category,
+// were stopped by an operator
+type Pause struct {
+	Scope    string     `json:"scope"`  // PauseService or PauseCategory
+	Target   string     `json:"target"` // Service ID or category name
+	Reason   string     `json:"reason,omitempty"`
+	PausedBy string     `json:"paused_by,omitempty"`
+	PausedAt time.Time  `json:"paused_at"`
+	Until    *time.Time `json:"until,omitempty"` // Automatic resume, nil = until resumed
+}
+
+// Expired reports whether the pause ended at now
+func (p Pause) Expired(now time.Time) bool {
+	return p.Until != nil && !now.Before(*p.Until)
+}
@@ -0,0 +1,377 @@
+package models
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Registry event kinds
+const (
+	EventAdded        = "added"        // A service was added
+	EventReconfigured = "reconfigured" // Declared or container fields changed (config reload, discovery)
+	EventUpdated      = "updated"      // Runtime state changed (checks, breaker, TLS, DNS, ...)
+	EventRemoved      = "removed"      // A service was removed
+)
+
+// Event is one change of the registry. Service is the record after the
+// change, or the removed record; Previous is the record before it (zero for
+// additions). Version increases by one with every change. Subscribers share
+// the records of an event and must not modify their slices.
+type Event struct {
+	Kind     string
+	Service  Service
+	Previous Service
+	Version  uint64
+}
+
+// maxPending bounds the events queued for one subscriber. A subscriber that
+// falls further behind is dropped and its channel closed.
+const maxPending = 10000
+
+// Registry holds all services. Records are copy-on-write: readers get copies,
+// writers go through Update (or Add, Remove, Sync, MergeDiscovered) and every
+// change is published to the subscribers in order.
+type Registry struct {
+	mu         sync.RWMutex
+	services   map[string]*Service // Never modified in place once stored
+	categories map[string]CategoryMeta
+	version    uint64
+
+	subsMu sync.Mutex
+	subs   map[*subscriber]struct{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		services:   make(map[string]*Service),
+		categories: make(map[string]CategoryMeta),
+		subs:       make(map[*subscriber]struct{}),
+	}
+}
+
+// Get returns a copy of a service
+func (r *Registry) Get(id string) (Service, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.services[id]
+	if !ok {
+		return Service{}, false
+	}
+	return s.Clone(), true
+}
+
+// GetAll returns a copy of every service, ordered by ID
+func (r *Registry) GetAll() []Service {
+	list, _ := r.Snapshot()
+	return list
+}
+
+// Snapshot returns a copy of every service, ordered by ID, and the version
+// it reflects. Events up to that version are already part of the snapshot.
+func (r *Registry) Snapshot() ([]Service, uint64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Service, 0, len(r.services))
+	for _, s := range r.services {
+		list = append(list, s.Clone())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, r.version
+}
+
+// Len returns the number of services
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.services)
+}
+
+// Add stores s, replacing a service with the same ID
+func (r *Registry) Add(s Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s = s.Clone()
+	if prev, ok := r.services[s.ID]; ok {
+		r.services[s.ID] = &s
+		r.publish(EventReconfigured, &s, prev)
+		return
+	}
+	r.services[s.ID] = &s
+	r.publish(EventAdded, &s, nil)
+}
+
+// Update applies fn to a copy of a service and stores the copy when fn
+// reports a change. It returns the resulting record, or false when the
+// service does not exist. fn runs with the registry locked and must not
+// call back into it.
+func (r *Registry) Update(id string, fn func(s *Service) bool) (Service, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.services[id]
+	if !ok {
+		return Service{}, false
+	}
+	next := cur.Clone()
+	if !fn(&next) {
+		return cur.Clone(), true
+	}
+	next.ID = id
+	r.services[id] = &next
+	r.publish(EventUpdated, &next, cur)
+	return next.Clone(), true
+}
+
+// Remove deletes a service from the registry
+func (r *Registry) Remove(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.services[id]
+	if !ok {
+		return false
+	}
+	delete(r.services, id)
+	r.publish(EventRemoved, s, s)
+	return true
+}
+
+// SyncResult lists what Sync changed
+type SyncResult struct {
+	Added     []string `json:"added"`
+	Updated   []string `json:"updated"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Sync makes the registry match the desired services.
+// Existing services keep their runtime state; new ones are added and
+// services missing from desired are removed, except discovered ones
+// which are owned by MergeDiscovered.
+func (r *Registry) Sync(desired []Service) SyncResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := SyncResult{Added: []string{}, Updated: []string{}, Removed: []string{}}
+	seen := make(map[string]bool, len(desired))
+
+	for i := range desired {
+		cfg := desired[i].Clone()
+		if cfg.ID == "" || seen[cfg.ID] {
+			continue
+		}
+		seen[cfg.ID] = true
+
+		cur, ok := r.services[cfg.ID]
+		if !ok {
+			r.services[cfg.ID] = &cfg
+			r.publish(EventAdded, &cfg, nil)
+			res.Added = append(res.Added, cfg.ID)
+			continue
+		}
+		next := cur.Clone()
+		// A declared service takes over a previously discovered one
+		next.Source = cfg.Source
+		if next.ApplyConfig(&cfg) {
+			r.services[cfg.ID] = &next
+			r.publish(EventReconfigured, &next, cur)
+			res.Updated = append(res.Updated, cfg.ID)
+		} else {
+			if next.Source != cur.Source {
+				r.services[cfg.ID] = &next
+				r.publish(EventUpdated, &next, cur)
+			}
+			res.Unchanged++
+		}
+	}
+
+	for id, s := range r.services {
+		if !seen[id] && s.Source != SourceDocker {
+			delete(r.services, id)
+			r.publish(EventRemoved, s, s)
+			res.Removed = append(res.Removed, id)
+		}
+	}
+
+	sort.Strings(res.Added)
+	sort.Strings(res.Updated)
+	sort.Strings(res.Removed)
+	return res
+}
+
+// SourceDocker marks services found by Docker discovery
+const SourceDocker = "docker"
+
+// MergeDiscovered folds services found by Docker discovery into the registry.
+// Known services (matched by ID or ContainerName) get their container fields
+// refreshed; unknown ones are added with Source "docker"; discovered services
+// whose container disappeared are removed. Declared services are never removed.
+func (r *Registry) MergeDiscovered(found []Service) SyncResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := SyncResult{Added: []string{}, Updated: []string{}, Removed: []string{}}
+	byContainer := make(map[string]*Service, len(r.services))
+	for _, s := range r.services {
+		if s.ContainerName != "" {
+			byContainer[s.ContainerName] = s
+		}
+	}
+
+	seen := make(map[string]bool, len(found))
+	for i := range found {
+		d := found[i].Clone()
+		cur, ok := r.services[d.ID]
+		if !ok && d.ContainerName != "" {
+			cur, ok = byContainer[d.ContainerName]
+		}
+		if !ok {
+			d.Source = SourceDocker
+			if d.Status == "" {
+				d.Status = StatusUnknown
+			}
+			r.services[d.ID] = &d
+			r.publish(EventAdded, &d, nil)
+			seen[d.ID] = true
+			res.Added = append(res.Added, d.ID)
+			continue
+		}
+
+		seen[cur.ID] = true
+		changed := cur.Image != d.Image ||
+			cur.ContainerState != d.ContainerState ||
+			!reflect.DeepEqual(cur.Networks, d.Networks) ||
+			(d.ContainerName != "" && cur.ContainerName != d.ContainerName)
+		if !changed && (cur.Version != "" || d.Version == "") {
+			res.Unchanged++
+			continue
+		}
+		next := cur.Clone()
+		next.Image = d.Image
+		next.ContainerState = d.ContainerState
+		next.Networks = d.Networks
+		if d.ContainerName != "" {
+			next.ContainerName = d.ContainerName
+		}
+		// DockerName belongs to the checker once set: it is the host that answered
+		if next.DockerName == "" {
+			next.DockerName = d.DockerName
+		}
+		if next.Version == "" {
+			next.Version = d.Version
+		}
+		r.services[next.ID] = &next
+		if changed {
+			r.publish(EventReconfigured, &next, cur)
+			res.Updated = append(res.Updated, next.ID)
+		} else {
+			r.publish(EventUpdated, &next, cur)
+			res.Unchanged++
+		}
+	}
+
+	for id, s := range r.services {
+		if s.Source == SourceDocker && !seen[id] {
+			delete(r.services, id)
+			r.publish(EventRemoved, s, s)
+			res.Removed = append(res.Removed, id)
+		}
+	}
+
+	sort.Strings(res.Added)
+	sort.Strings(res.Updated)
+	sort.Strings(res.Removed)
+	return res
+}
+
+// Subscribe returns a channel receiving every change from now on, in order,
+// and a function that ends the subscription and closes the channel.
+// Subscribe before taking a Snapshot to miss nothing.
+func (r *Registry) Subscribe() (<-chan Event, func()) {
+	sub := &subscriber{
+		ch:   make(chan Event),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	r.subsMu.Lock()
+	r.subs[sub] = struct{}{}
+	r.subsMu.Unlock()
+	go sub.pump()
+
+	cancel := func() {
+		r.subsMu.Lock()
+		if _, ok := r.subs[sub]; ok {
+			delete(r.subs, sub)
+			close(sub.done)
+		}
+		r.subsMu.Unlock()
+	}
+	return sub.ch, cancel
+}
+
+// publish queues an event for every subscriber. It is called with r.mu
+// held, which keeps events in version order, and never blocks.
+func (r *Registry) publish(kind string, s, prev *Service) {
+	r.version++
+	ev := Event{Kind: kind, Service: s.Clone(), Version: r.version}
+	if prev != nil {
+		ev.Previous = prev.Clone()
+	}
+
+	r.subsMu.Lock()
+	defer r.subsMu.Unlock()
+	for sub := range r.subs {
+		if !sub.push(ev) {
+			delete(r.subs, sub)
+			close(sub.done)
+		}
+	}
+}
+
+// subscriber buffers events between the registry and a consumer
+type subscriber struct {
+	ch   chan Event
+	wake chan struct{}
+	done chan struct{}
+
+	mu      sync.Mutex
+	pending []Event
+}
+
+// push queues ev and reports false when the subscriber fell too far behind
+func (s *subscriber) push(ev Event) bool {
+	s.mu.Lock()
+	if len(s.pending) >= maxPending {
+		s.mu.Unlock()
+		return false
+	}
+	s.pending = append(s.pending, ev)
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pump delivers queued events until the subscription ends
+func (s *subscriber) pump() {
+	defer close(s.ch)
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+		s.mu.Lock()
+		batch := s.pending
+		s.pending = nil
+		s.mu.Unlock()
+		for _, ev := range batch {
+			select {
+			case s.ch <- ev:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
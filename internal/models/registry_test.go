@@ -0,0 +1,255 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(n int) *Registry {
+	r := NewRegistry()
+	for i := 0; i < n; i++ {
+		r.Add(Service{
+			ID:     fmt.Sprintf("svc-%02d", i),
+			Status: StatusUnknown,
+			Tags:   []string{"a", "b"},
+		})
+	}
+	return r
+}
+
+func TestConcurrentUpdateSnapshotSubscribe(t *testing.T) {
+	const (
+		services = 8
+		writers  = 4
+		updates  = 200
+	)
+	r := newTestRegistry(services)
+
+	events, cancel := r.Subscribe()
+	defer cancel()
+	_, start := r.Snapshot()
+
+	var received sync.WaitGroup
+	received.Add(1)
+	var gotErr error
+	go func() {
+		defer received.Done()
+		want := start + 1
+		for ev := range events {
+			if ev.Version != want && gotErr == nil {
+				gotErr = fmt.Errorf("got version %d, want %d", ev.Version, want)
+			}
+			want = ev.Version + 1
+			if want > start+writers*updates {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				id := fmt.Sprintf("svc-%02d", (w+i)%services)
+				r.Update(id, func(s *Service) bool {
+					s.ResponseMs++
+					s.HealthHistory = append(s.HealthHistory, StatusHealthy)
+					return true
+				})
+			}
+		}(w)
+	}
+	// Readers and short-lived subscribers run against the writers
+	for rd := 0; rd < 2; rd++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				list, _ := r.Snapshot()
+				for j := range list {
+					list[j].Tags[0] = "changed"
+				}
+				if _, ok := r.Get("svc-00"); !ok {
+					t.Error("svc-00 missing")
+				}
+				_, stop := r.Subscribe()
+				stop()
+			}
+		}()
+	}
+	wg.Wait()
+
+	done := make(chan struct{})
+	go func() { received.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+	if gotErr != nil {
+		t.Fatal(gotErr)
+	}
+
+	var total int64
+	for _, s := range r.GetAll() {
+		total += s.ResponseMs
+		if s.Tags[0] != "a" {
+			t.Errorf("%s: stored tags changed through a snapshot: %v", s.ID, s.Tags)
+		}
+	}
+	if total != writers*updates {
+		t.Errorf("got %d updates applied, want %d", total, writers*updates)
+	}
+}
+
+func TestEventsArriveInVersionOrder(t *testing.T) {
+	r := newTestRegistry(1)
+	events, cancel := r.Subscribe()
+	defer cancel()
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		r.Update("svc-00", func(s *Service) bool {
+			s.ResponseMs = int64(i)
+			return true
+		})
+	}
+	r.Remove("svc-00")
+
+	var last uint64
+	for i := 0; i <= n; i++ {
+		select {
+		case ev := <-events:
+			if last != 0 && ev.Version != last+1 {
+				t.Fatalf("event %d: got version %d after %d", i, ev.Version, last)
+			}
+			last = ev.Version
+			if i < n && (ev.Kind != EventUpdated || ev.Service.ResponseMs != int64(i)) {
+				t.Fatalf("event %d: got %s with %d", i, ev.Kind, ev.Service.ResponseMs)
+			}
+			if i == n && ev.Kind != EventRemoved {
+				t.Fatalf("last event: got %s, want %s", ev.Kind, EventRemoved)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+}
+
+func TestUpdateWithoutChangePublishesNothing(t *testing.T) {
+	r := newTestRegistry(1)
+	events, cancel := r.Subscribe()
+	defer cancel()
+
+	_, before := r.Snapshot()
+	r.Update("svc-00", func(s *Service) bool { return false })
+	if _, after := r.Snapshot(); after != before {
+		t.Errorf("version moved from %d to %d", before, after)
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected %s event", ev.Kind)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	r := newTestRegistry(1)
+	events, cancel := r.Subscribe()
+	defer cancel()
+
+	// The pump holds at most one batch while blocked on the channel, so
+	// twice maxPending overflows the queue whatever it already took
+	for i := 0; i < 2*maxPending+1; i++ {
+		r.Update("svc-00", func(s *Service) bool {
+			s.ResponseMs++
+			return true
+		})
+	}
+
+	r.subsMu.Lock()
+	remaining := len(r.subs)
+	r.subsMu.Unlock()
+	if remaining != 0 {
+		t.Fatalf("got %d subscribers, want the slow one dropped", remaining)
+	}
+
+	timeout := time.After(5 * time.Second)
+	count := 0
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				if count >= 2*maxPending+1 {
+					t.Errorf("received all %d events from a dropped subscriber", count)
+				}
+				return
+			}
+			count++
+		case <-timeout:
+			t.Fatal("channel of the dropped subscriber was not closed")
+		}
+	}
+}
+
+func TestCloneIsolatesSlices(t *testing.T) {
+	r := NewRegistry()
+	r.Add(Service{
+		ID:            "svc",
+		Tags:          []string{"go"},
+		HealthHistory: []string{StatusHealthy},
+		Networks:      []string{"net"},
+		DependsOn:     []string{"db"},
+		ImpactedBy:    []string{"db"},
+		TLS:           []CertStatus{{Host: "a.example"}},
+		DNS:           []DNSStatus{{Host: "a.example"}},
+	})
+
+	got, _ := r.Get("svc")
+	got.Tags[0] = "x"
+	got.HealthHistory[0] = "x"
+	got.Networks[0] = "x"
+	got.DependsOn[0] = "x"
+	got.ImpactedBy[0] = "x"
+	got.TLS[0].Host = "x"
+	got.DNS[0].Host = "x"
+	got.Tags = append(got.Tags, "y")
+
+	list := r.GetAll()
+	list[0].Tags[0] = "x"
+
+	updated, _ := r.Update("svc", func(s *Service) bool { return false })
+	updated.Networks[0] = "x"
+
+	stored, _ := r.Get("svc")
+	if stored.Tags[0] != "go" || len(stored.Tags) != 1 ||
+		stored.HealthHistory[0] != StatusHealthy ||
+		stored.Networks[0] != "net" ||
+		stored.DependsOn[0] != "db" ||
+		stored.ImpactedBy[0] != "db" ||
+		stored.TLS[0].Host != "a.example" ||
+		stored.DNS[0].Host != "a.example" {
+		t.Errorf("stored record changed through a copy: %+v", stored)
+	}
+}
+
+func TestMergeDiscoveredKeepsDeclaredServices(t *testing.T) {
+	r := NewRegistry()
+	r.Sync([]Service{{ID: "declared", Port: 8080}})
+
+	res := r.MergeDiscovered([]Service{{ID: "found", Image: "img:1"}})
+	if len(res.Added) != 1 || res.Added[0] != "found" {
+		t.Fatalf("got added %v, want [found]", res.Added)
+	}
+	res = r.MergeDiscovered(nil)
+	if len(res.Removed) != 1 || res.Removed[0] != "found" {
+		t.Fatalf("got removed %v, want [found]", res.Removed)
+	}
+	if _, ok := r.Get("declared"); !ok {
+		t.Error("declared service removed by discovery")
+	}
+}
@@ -1,70 +1,124 @@
 package models
 
 import (
-	"sync"
+	"reflect"
 	"time"
 )
 
 // Service represents a monitored microservice
 type Service struct {
-	ID                  string    `json:"id"`
-	Name                string    `json:"name"`
-	DisplayName         string    `json:"display_name"`
-	Description         string    `json:"description"`
-	Category            string    `json:"category"`
-	Port                int       `json:"port"`
-	DockerName          string    `json:"docker_name"`
-	RepoURL             string    `json:"repo_url"`
-	ExampleURL          string    `json:"example_url"`
-	HealthURL           string    `json:"health_url"`
-	Status              string    `json:"status"`         // healthy, degraded, unhealthy
-	HealthStatus        string    `json:"health_status"`  // /health endpoint status
-	ExampleStatus       string    `json:"example_status"` // ExampleURL status
-	LastError           string    `json:"last_error,omitempty"`
-	TestStatus          string    `json:"test_status"`
-	TestError           string    `json:"test_error,omitempty"`
-	Version             string    `json:"version"`
-	LatestVersion       string    `json:"latest_version,omitempty"` // Latest available Docker image version
-	UpdateAvailable     bool      `json:"update_available"`         // True if Version != LatestVersion
-	LastChecked         time.Time `json:"last_checked"`
-	ResponseMs          int64     `json:"response_ms"`
-	Tags                []string  `json:"tags"`
-	HealthHistory       []string  `json:"health_history,omitempty"`     // Last 5 checks
-	ConsecutiveFailures int       `json:"-"`                            // Internal counter for circuit breaker
-	CircuitOpenUntil    time.Time `json:"circuit_open_until,omitempty"` // When to try again if breaker is open
+	ID              string        `json:"id"`
+	Name            string        `json:"name"`
+	DisplayName     string        `json:"display_name"`
+	Description     string        `json:"description"`
+	Category        string        `json:"category"`
+	Port            int           `json:"port"`
+	DockerName      string        `json:"docker_name"`
+	RepoURL         string        `json:"repo_url"`
+	ExampleURL      string        `json:"example_url"`
+	HealthURL       string        `json:"health_url"`
+	Status          string        `json:"status"`         // See Status* for the values
+	HealthStatus    string        `json:"health_status"`  // /health endpoint status
+	ExampleStatus   string        `json:"example_status"` // ExampleURL status
+	LastError       string        `json:"last_error,omitempty"`
+	TestStatus      string        `json:"test_status"`
+	TestError       string        `json:"test_error,omitempty"`
+	Version         string        `json:"version"`
+	LatestVersion   string        `json:"latest_version,omitempty"` // Latest available Docker image version
+	UpdateAvailable bool          `json:"update_available"`         // True if Version != LatestVersion
+	LastChecked     time.Time     `json:"last_checked"`
+	ResponseMs      int64         `json:"response_ms"`
+	Timings         *PhaseTimings `json:"timings,omitempty"` // Phase breakdown of the last passing health request
+	Tags            []string      `json:"tags"`
+	SLO             float64       `json:"slo,omitempty"`             // Target availability percent (e.g. 99.5), 0 = dashboard default
+	Check           *CheckSpec    `json:"check,omitempty"`           // Custom health check definition, nil = default behaviour
+	Interval        Duration      `json:"interval,omitempty"`        // Time between checks, 0 = CHECK_INTERVAL
+	Timeout         Duration      `json:"timeout,omitempty"`         // Per-request timeout, 0 = dashboard default
+	Priority        int           `json:"priority,omitempty"`        // Higher runs first when checks queue up
+	DependsOn       []string      `json:"depends_on,omitempty"`      // IDs of the services this one calls
+	HealthHistory   []string      `json:"health_history,omitempty"`  // Last 5 checks
+	Breaker         BreakerState  `json:"breaker"`                   // Circuit breaker state
+	Maintenance     string        `json:"maintenance,omitempty"`     // ID of the active maintenance window or silence
+	Paused          *Pause        `json:"paused,omitempty"`          // Who paused checks of the service (or its category) and why
+	LastTested      time.Time     `json:"last_tested,omitempty"`     // When the active link test last ran
+	Source          string        `json:"source,omitempty"`          // "" for config/services.json, "docker" for discovered services
+	ContainerName   string        `json:"container_name,omitempty"`  // Container found by Docker discovery; DockerName is the host that answered
+	Image           string        `json:"image,omitempty"`           // Container image reported by Docker discovery
+	ContainerState  string        `json:"container_state,omitempty"` // running, exited, ... (Docker discovery)
+	Networks        []string      `json:"networks,omitempty"`        // Docker networks the container is attached to
+	NetworkStatus   string        `json:"network_status,omitempty"`  // attached, missing, no_container (network audit)
+	TLS             []CertStatus  `json:"tls,omitempty"`             // Certificates of the public hosts (TLS checker)
+	DNS             []DNSStatus   `json:"dns,omitempty"`             // Records of the public hosts (DNS checker)
+	RootCause       string        `json:"root_cause,omitempty"`      // Classified cause of the last check result (see RootCause*)
+	ImpactedBy      []string      `json:"impacted_by,omitempty"`     // Failing upstream services this one's failure is blamed on
+	Diagnosis       *Diagnosis    `json:"-"`                         // Requests made by the last check, served by the detail view
 }
 
-// Registry holds all services
-type Registry struct {
-	Services map[string]*Service
-	Mu       sync.RWMutex
-}
+// ApplyConfig copies the declared (config file) fields of cfg onto s,
+// leaving runtime state such as status, history and breaker counters alone.
+// It reports whether any declared field changed.
+func (s *Service) ApplyConfig(cfg *Service) bool {
+	changed := s.Name != cfg.Name ||
+		s.DisplayName != cfg.DisplayName ||
+		s.Description != cfg.Description ||
+		s.Category != cfg.Category ||
+		s.Port != cfg.Port ||
+		s.RepoURL != cfg.RepoURL ||
+		s.ExampleURL != cfg.ExampleURL ||
+		s.HealthURL != cfg.HealthURL ||
+		s.SLO != cfg.SLO ||
+		s.Interval != cfg.Interval ||
+		s.Timeout != cfg.Timeout ||
+		s.Priority != cfg.Priority ||
+		!reflect.DeepEqual(s.Tags, cfg.Tags) ||
+		!reflect.DeepEqual(s.DependsOn, cfg.DependsOn) ||
+		!reflect.DeepEqual(s.Check, cfg.Check)
 
-func NewRegistry() *Registry {
-	return &Registry{
-		Services: make(map[string]*Service),
+	s.Name = cfg.Name
+	s.DisplayName = cfg.DisplayName
+	s.Description = cfg.Description
+	s.Category = cfg.Category
+	s.Port = cfg.Port
+	s.RepoURL = cfg.RepoURL
+	s.ExampleURL = cfg.ExampleURL
+	s.HealthURL = cfg.HealthURL
+	s.SLO = cfg.SLO
+	s.Interval = cfg.Interval
+	s.Timeout = cfg.Timeout
+	s.Priority = cfg.Priority
+	s.Tags = cfg.Tags
+	s.DependsOn = cfg.DependsOn
+	s.Check = cfg.Check
+	// DockerName is left alone: the checker rewrites it with the host that
+	// actually answered, so the declared value is only a starting hint.
+	if s.DockerName == "" {
+		s.DockerName = cfg.DockerName
 	}
+	return changed
 }
 
-func (r *Registry) AddService(s *Service) {
-	r.Mu.Lock()
-	defer r.Mu.Unlock()
-	r.Services[s.ID] = s
-}
-
-func (r *Registry) GetAll() []*Service {
-	r.Mu.RLock()
-	defer r.Mu.RUnlock()
-	list := make([]*Service, 0, len(r.Services))
-	for _, s := range r.Services {
-		list = append(list, s)
+// Clone returns a copy of s whose slices can be modified without affecting s.
+// Pointer fields and slice elements are shared: they are replaced on change,
+// never modified in place.
+func (s *Service) Clone() Service {
+	c := *s
+	c.Tags = cloneStrings(s.Tags)
+	c.HealthHistory = cloneStrings(s.HealthHistory)
+	c.Networks = cloneStrings(s.Networks)
+	c.DependsOn = cloneStrings(s.DependsOn)
+	c.ImpactedBy = cloneStrings(s.ImpactedBy)
+	if s.TLS != nil {
+		c.TLS = append([]CertStatus(nil), s.TLS...)
 	}
-	return list
+	if s.DNS != nil {
+		c.DNS = append([]DNSStatus(nil), s.DNS...)
+	}
+	return c
 }
 
-func (r *Registry) Get(id string) (*Service, bool) {
-	r.Mu.RLock()
-	defer r.Mu.RUnlock()
-	s, exists := r.Services[id]
-	return s, exists
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
 }
@@ -0,0 +1,49 @@
+package models
+
+// Service statuses
+const (
+	StatusUnknown     = "unknown"      // Not checked yet
+	StatusHealthy     = "healthy"      // Health check and example URL pass
+	StatusDegraded    = "degraded"     // Health check passes, but the example URL fails or the service is slow
+	StatusUnhealthy   = "unhealthy"    // Health check fails
+	StatusCircuitOpen = "circuit_open" // Circuit breaker open or half-open, checks are suspended
+	StatusMaintenance = "maintenance"  // Inside a maintenance window
+	StatusPaused      = "paused"       // Checks paused by an operator
+)
+
+// Statuses lists every service status, best first
+var Statuses = []string{
+	StatusHealthy,
+	StatusDegraded,
+	StatusUnhealthy,
+	StatusCircuitOpen,
+	StatusMaintenance,
+	StatusPaused,
+	StatusUnknown,
+}
+
+// IsStatus reports whether s is a known service status
+func IsStatus(s string) bool {
+	for _, v := range Statuses {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusUp reports whether a status counts as available for uptime.
+// Degraded services still serve requests and count as up.
+func StatusUp(s string) bool {
+	return s == StatusHealthy || s == StatusDegraded
+}
+
+// StatusCounted reports whether a status is included in uptime at all.
+// Maintenance, paused and unknown periods are neither up nor down.
+func StatusCounted(s string) bool {
+	switch s {
+	case StatusMaintenance, StatusPaused, StatusUnknown, "":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Certificate states, from best to worst
+const (
+	CertOK       = "ok"
+	CertError    = "error"    // Handshake failed, nothing to inspect
+	CertWarning  = "warning"  // Expires within the warning threshold
+	CertCritical = "critical" // Expires within the critical threshold
+	CertInvalid  = "invalid"  // Untrusted chain or hostname mismatch
+	CertExpired  = "expired"
+)
+
+// certSeverity orders certificate states for picking the worst one
+var certSeverity = map[string]int{
+	CertOK:       0,
+	CertError:    1,
+	CertWarning:  2,
+	CertCritical: 3,
+	CertInvalid:  4,
+	CertExpired:  5,
+}
+
+// CertStatus is the result of inspecting the TLS certificate of one public host
+type CertStatus struct {
+	Host             string    `json:"host"`
+	Status           string    `json:"status"`
+	Subject          string    `json:"subject,omitempty"`
+	Issuer           string    `json:"issuer,omitempty"`
+	SANs             []string  `json:"sans,omitempty"`
+	NotBefore        time.Time `json:"not_before,omitempty"`
+	NotAfter         time.Time `json:"not_after,omitempty"`
+	DaysRemaining    int       `json:"days_remaining"`
+	HostnameMismatch bool      `json:"hostname_mismatch,omitempty"`
+	ChainError       string    `json:"chain_error,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	CheckedAt        time.Time `json:"checked_at"`
+}
+
+// WorseThan reports whether c is in a worse state than other
+func (c CertStatus) WorseThan(other CertStatus) bool {
+	return certSeverity[c.Status] > certSeverity[other.Status]
+}
+
+// WorstCert returns the certificate in the worst state, or nil when there are none
+func WorstCert(certs []CertStatus) *CertStatus {
+	var worst *CertStatus
+	for i := range certs {
+		if worst == nil || certs[i].WorseThan(*worst) {
+			worst = &certs[i]
+		}
+	}
+	return worst
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// BreakerOptions configures the per-service circuit breaker
+type BreakerOptions struct {
+	Threshold   int           // Consecutive failing checks that open the breaker
+	CoolDown    time.Duration // Open period after the first trip, doubled on every failed probe
+	MaxCoolDown time.Duration // Upper bound of the open period
+}
+
+// DefaultBreakerOptions returns the breaker settings used when nothing is configured
+func DefaultBreakerOptions() BreakerOptions {
+	return BreakerOptions{
+		Threshold:   5,
+		CoolDown:    5 * time.Minute,
+		MaxCoolDown: time.Hour,
+	}
+}
+
+// coolDown returns the open period after the given number of consecutive trips
+func (o BreakerOptions) coolDown(trips int) time.Duration {
+	d := o.CoolDown
+	for i := 1; i < trips && d < o.MaxCoolDown; i++ {
+		d *= 2
+	}
+	if d > o.MaxCoolDown {
+		d = o.MaxCoolDown
+	}
+	return d
+}
+
+// SetBreaker replaces the circuit breaker settings. Unset fields keep their defaults.
+func (m *Monitor) SetBreaker(opts BreakerOptions) {
+	def := DefaultBreakerOptions()
+	if opts.Threshold <= 0 {
+		opts.Threshold = def.Threshold
+	}
+	if opts.CoolDown <= 0 {
+		opts.CoolDown = def.CoolDown
+	}
+	if opts.MaxCoolDown < opts.CoolDown {
+		opts.MaxCoolDown = opts.CoolDown
+	}
+	m.breaker = opts
+}
+
+// admit decides whether a check may run. It moves an open breaker whose
+// cool-down has ended to half-open and reports whether the check is a probe.
+// Must be called with the registry lock held.
+func (m *Monitor) admit(b *models.BreakerState, now time.Time) (run, probe bool) {
+	switch b.State {
+	case models.BreakerOpen:
+		if now.Before(b.OpenUntil) {
+			return false, false
+		}
+		b.State = models.BreakerHalfOpen
+		return true, true
+	case models.BreakerHalfOpen:
+		return true, true
+	}
+	return true, false
+}
+
+// settle applies a check result to the breaker and reports whether it opened
+// from closed. A failed half-open probe reopens it with a longer cool-down.
+// Must be called with the registry lock held.
+func (m *Monitor) settle(b *models.BreakerState, healthy bool, errMsg string, now time.Time) bool {
+	if healthy {
+		*b = models.BreakerState{State: models.BreakerClosed}
+		return false
+	}
+	b.Failures++
+	if b.State != models.BreakerHalfOpen && b.Failures < m.breaker.Threshold {
+		b.State = models.BreakerClosed
+		return false
+	}
+	b.Trips++
+	b.State = models.BreakerOpen
+	b.OpenedAt = now
+	b.OpenUntil = now.Add(m.breaker.coolDown(b.Trips))
+	b.Reason = errMsg
+	return b.Trips == 1
+}
+
+// ResetBreaker closes the breaker of a service and makes it due for a check now.
+// It returns false when the service does not exist.
+func (m *Monitor) ResetBreaker(id string) (models.BreakerState, bool) {
+	var prev models.BreakerState
+	svc, ok := m.registry.Update(id, func(s *models.Service) bool {
+		prev = s.Breaker
+		s.Breaker = models.BreakerState{State: models.BreakerClosed}
+		if s.Status == models.StatusCircuitOpen {
+			s.Status = models.StatusUnknown
+		}
+		return true
+	})
+	if !ok {
+		return models.BreakerState{}, false
+	}
+	if prev.State == "" {
+		prev.State = models.BreakerClosed
+	}
+	m.sched.update(svc)
+	return prev, true
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestBreakerCoolDown(t *testing.T) {
+	opts := BreakerOptions{Threshold: 3, CoolDown: time.Minute, MaxCoolDown: 5 * time.Minute}
+	for trips, want := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		3: 4 * time.Minute,
+		4: 5 * time.Minute, // Capped
+		9: 5 * time.Minute,
+	} {
+		if got := opts.coolDown(trips); got != want {
+			t.Errorf("trip %d: got %s, want %s", trips, got, want)
+		}
+	}
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	m := &Monitor{}
+	m.SetBreaker(BreakerOptions{Threshold: 3, CoolDown: time.Minute, MaxCoolDown: 3 * time.Minute})
+	var b models.BreakerState
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// check runs a check at now with the given result and reports what happened
+	check := func(healthy bool) (run, probe, tripped bool) {
+		run, probe = m.admit(&b, now)
+		if run {
+			tripped = m.settle(&b, healthy, "HTTP 503", now)
+		}
+		return run, probe, tripped
+	}
+
+	// Below the threshold the breaker stays closed
+	for i := 1; i < 3; i++ {
+		if run, probe, tripped := check(false); !run || probe || tripped || b.State != models.BreakerClosed || b.Failures != i {
+			t.Fatalf("failure %d: got %v %v %v %+v", i, run, probe, tripped, b)
+		}
+	}
+	// The third failure trips it, and only the first trip is reported
+	if _, _, tripped := check(false); !tripped || b.State != models.BreakerOpen || b.Trips != 1 || !b.OpenUntil.Equal(now.Add(time.Minute)) || b.Reason != "HTTP 503" {
+		t.Fatalf("got tripped=%v %+v", tripped, b)
+	}
+	if run, _, _ := check(false); run {
+		t.Fatal("check ran while the breaker was open")
+	}
+
+	// After the cool-down a failing probe re-trips it with a doubled cool-down
+	now = b.OpenUntil
+	if run, probe, tripped := check(false); !run || !probe || tripped || b.State != models.BreakerOpen || b.Trips != 2 || !b.OpenUntil.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("got %v %v %v %+v", run, probe, tripped, b)
+	}
+	now = b.OpenUntil
+	check(false)
+	if !b.OpenUntil.Equal(now.Add(3 * time.Minute)) {
+		t.Fatalf("got cool-down until %s, want the 3m cap", b.OpenUntil)
+	}
+
+	// A half-open breaker admits the probe until it settles
+	now = b.OpenUntil
+	if run, probe := m.admit(&b, now); !run || !probe || b.State != models.BreakerHalfOpen {
+		t.Fatalf("got %v %v %+v", run, probe, b)
+	}
+	if run, probe := m.admit(&b, now); !run || !probe {
+		t.Fatalf("half-open breaker refused the probe")
+	}
+
+	// A passing probe closes it and forgets the trips
+	if m.settle(&b, true, "", now); b.State != models.BreakerClosed || b.Failures != 0 || b.Trips != 0 {
+		t.Fatalf("got %+v after a passing probe", b)
+	}
+	if run, probe, _ := check(true); !run || probe {
+		t.Fatalf("got %v %v from a closed breaker", run, probe)
+	}
+}
+
+func TestResetBreaker(t *testing.T) {
+	registry := models.NewRegistry()
+	registry.Add(models.Service{ID: "svc", Status: models.StatusCircuitOpen, Breaker: models.BreakerState{
+		State: models.BreakerOpen, Failures: 7, Trips: 2, OpenUntil: time.Now().Add(time.Hour),
+	}})
+	m := NewMonitor(registry, nil)
+
+	prev, ok := m.ResetBreaker("svc")
+	if !ok || prev.State != models.BreakerOpen || prev.Trips != 2 {
+		t.Fatalf("got %+v, %v", prev, ok)
+	}
+	svc, _ := registry.Get("svc")
+	if !svc.Breaker.Closed() || svc.Breaker.Failures != 0 || svc.Status != models.StatusUnknown {
+		t.Errorf("got %s %+v after reset", svc.Status, svc.Breaker)
+	}
+	if run, probe := m.admit(&svc.Breaker, time.Now()); !run || probe {
+		t.Errorf("got %v %v after reset", run, probe)
+	}
+	if _, ok := m.ResetBreaker("missing"); ok {
+		t.Error("reset a missing service")
+	}
+}
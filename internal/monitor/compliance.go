@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/compliance"
+)
+
+// DefaultComplianceInterval is the time between two compliance scans of RunCompliance
+const DefaultComplianceInterval = 15 * time.Minute
+
+// complianceWorkers bounds the services scanned at once
+const complianceWorkers = 8
+
+// ScanCompliance runs a standardization scan on all services and records the
+// reports, in registry order. Concurrent calls wait for each other.
+func (m *Monitor) ScanCompliance() []compliance.ComplianceReport {
+	m.complianceMu.Lock()
+	defer m.complianceMu.Unlock()
+
+	services := m.registry.GetAll()
+	reports := make([]compliance.ComplianceReport, len(services))
+	client := &http.Client{Timeout: DefaultTimeout}
+
+	sem := make(chan struct{}, complianceWorkers)
+	var wg sync.WaitGroup
+	for i := range services {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = compliance.Scan(client, &services[i])
+			<-sem
+		}(i)
+	}
+	wg.Wait()
+	m.RecordCompliance(reports)
+	return reports
+}
+
+// RunCompliance runs ScanCompliance periodically, so the reports read by
+// /metrics and the service detail stay fresh. It is meant to run in its own goroutine.
+func (m *Monitor) RunCompliance(interval time.Duration) {
+	for {
+		m.ScanCompliance()
+		time.Sleep(interval)
+	}
+}
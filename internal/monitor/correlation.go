@@ -0,0 +1,467 @@
+package monitor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Failure classes shared by correlated services, most specific first
+const (
+	ClassIngress    = "ingress"     // Same public ingress address (nginx)
+	ClassDockerHost = "docker_host" // Internal checks fail on the host that last answered them
+	ClassError      = "error"       // Same error once service names, addresses and numbers are removed
+	ClassCategory   = "category"    // Same category
+)
+
+// classOrder ranks the classes when a service fits several groups of the same size
+var classOrder = []string{ClassIngress, ClassDockerHost, ClassError, ClassCategory}
+
+// Correlation event kinds
+const (
+	CorrelationOpened   = "correlation_opened"
+	CorrelationUpdated  = "correlation_updated"
+	CorrelationResolved = "correlation_resolved"
+)
+
+const (
+	// keepResolvedCorrelations is how many resolved correlations stay listed
+	keepResolvedCorrelations = 50
+	// maxPendingCorrelations bounds the events queued for one subscriber
+	maxPendingCorrelations = 64
+	// maxErrorKey bounds the normalised error used as a class key
+	maxErrorKey = 160
+)
+
+// CorrelationOptions configures the grouping of simultaneous failures
+type CorrelationOptions struct {
+	Window      time.Duration // Failures starting at most this far apart are simultaneous
+	MinServices int           // Services a group needs to be reported
+}
+
+// DefaultCorrelationOptions groups three or more failures starting within two minutes
+func DefaultCorrelationOptions() CorrelationOptions {
+	return CorrelationOptions{
+		Window:      2 * time.Minute,
+		MinServices: 3,
+	}
+}
+
+// Correlation is a group of services failing at the same time for what is
+// probably one common cause
+type Correlation struct {
+	ID            string         `json:"id"`
+	Class         string         `json:"class"` // One of the Class* values
+	Key           string         `json:"key"`   // The shared ingress, host, error or category
+	ProbableCause string         `json:"probable_cause"`
+	RootCause     string         `json:"root_cause"`  // Most common root cause of the services
+	RootCauses    map[string]int `json:"root_causes"` // Services per root cause
+	Services      []string       `json:"services"`
+	StartedAt     time.Time      `json:"started_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	ResolvedAt    *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// CorrelationEvent is one change of a correlation
+type CorrelationEvent struct {
+	Kind        string      `json:"type"`
+	Correlation Correlation `json:"correlation"`
+}
+
+// failure is a failing service as seen by the correlator
+type failure struct {
+	since     time.Time
+	rootCause string
+	keys      map[string]string // Class -> key
+}
+
+// correlator groups simultaneous failures sharing a failure class
+type correlator struct {
+	opts CorrelationOptions
+
+	mu       sync.Mutex
+	failing  map[string]*failure
+	active   map[string]*Correlation // Keyed by class:key
+	resolved []Correlation           // Newest last
+	subs     map[chan CorrelationEvent]struct{}
+}
+
+func newCorrelator(opts CorrelationOptions) *correlator {
+	return &correlator{
+		opts:    opts,
+		failing: make(map[string]*failure),
+		active:  make(map[string]*Correlation),
+		subs:    make(map[chan CorrelationEvent]struct{}),
+	}
+}
+
+// SetCorrelation replaces the correlation settings. Unset fields keep their
+// defaults. Call before Start.
+func (m *Monitor) SetCorrelation(opts CorrelationOptions) {
+	def := DefaultCorrelationOptions()
+	if opts.Window <= 0 {
+		opts.Window = def.Window
+	}
+	if opts.MinServices < 2 {
+		opts.MinServices = def.MinServices
+	}
+	m.correlator.opts = opts
+}
+
+// Correlations returns the active correlations, largest first, and the
+// recently resolved ones, newest first
+func (m *Monitor) Correlations() (active, resolved []Correlation) {
+	return m.correlator.list()
+}
+
+// SubscribeCorrelations returns a channel receiving correlation changes and a
+// function that ends the subscription. A subscriber that falls behind is
+// dropped and its channel closed.
+func (m *Monitor) SubscribeCorrelations() (<-chan CorrelationEvent, func()) {
+	c := m.correlator
+	ch := make(chan CorrelationEvent, maxPendingCorrelations)
+	c.mu.Lock()
+	c.subs[ch] = struct{}{}
+	c.mu.Unlock()
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.subs[ch]; ok {
+			delete(c.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// observe records the state of svc after a check and regroups the failures.
+// Only down services take part; degraded ones still answer.
+func (c *correlator) observe(svc models.Service, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !upstreamDown(svc.Status) {
+		if _, ok := c.failing[svc.ID]; !ok {
+			return
+		}
+		delete(c.failing, svc.ID)
+	} else {
+		f, ok := c.failing[svc.ID]
+		if !ok {
+			f = &failure{since: at}
+			c.failing[svc.ID] = f
+		}
+		f.rootCause = svc.RootCause
+		f.keys = c.classify(&svc)
+	}
+	c.regroup(at)
+}
+
+// forget drops a service that left the registry
+func (c *correlator) forget(id string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.failing[id]; !ok {
+		return
+	}
+	delete(c.failing, id)
+	c.regroup(at)
+}
+
+// failingStatus reports whether a status is degraded or down
+func failingStatus(status string) bool {
+	switch status {
+	case models.StatusDegraded, models.StatusUnhealthy, models.StatusCircuitOpen:
+		return true
+	}
+	return false
+}
+
+// classify returns the failure classes of a failing service
+func (c *correlator) classify(svc *models.Service) map[string]string {
+	keys := make(map[string]string, len(classOrder))
+	switch svc.RootCause {
+	case models.RootCauseProxy, models.RootCauseTLS, models.RootCauseDNS:
+		if ingress := ingressOf(svc); ingress != "" {
+			keys[ClassIngress] = ingress
+		}
+	case models.RootCauseContainerDown, models.RootCauseNetwork, models.RootCauseTimeout:
+		// The internal host that last answered: host.docker.internal is shared
+		// by every container reached through the Docker host
+		if svc.DockerName != "" {
+			keys[ClassDockerHost] = svc.DockerName
+		}
+	}
+	if e := normaliseError(svc); e != "" {
+		keys[ClassError] = e
+	}
+	if svc.Category != "" {
+		keys[ClassCategory] = svc.Category
+	}
+	return keys
+}
+
+// ingressOf returns the address the public hostname of svc resolved to
+// during its last check, falling back to the DNS checker's answers and then
+// to the parent domain
+func ingressOf(svc *models.Service) string {
+	if svc.Diagnosis != nil {
+		for _, a := range svc.Diagnosis.Attempts {
+			if a.Kind != models.AttemptPublicHealth && a.Kind != models.AttemptPublicExample {
+				continue
+			}
+			addr := strings.TrimSpace(strings.Split(a.DNS, ",")[0])
+			if net.ParseIP(addr) != nil {
+				return addr
+			}
+		}
+	}
+	for _, rec := range svc.DNS {
+		for _, ans := range rec.Answers {
+			if len(ans.A) > 0 {
+				return ans.A[0]
+			}
+		}
+	}
+	for _, raw := range []string{svc.HealthURL, svc.ExampleURL} {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		labels := strings.Split(u.Hostname(), ".")
+		if len(labels) > 2 {
+			labels = labels[len(labels)-2:]
+		}
+		return strings.Join(labels, ".")
+	}
+	return ""
+}
+
+var (
+	urlPattern    = regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^\s"]+`)
+	addrPattern   = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// normaliseError strips what differs between services from the last error,
+// so the same failure of different services yields the same key
+func normaliseError(svc *models.Service) string {
+	e := strings.ToLower(svc.LastError)
+	if e == "" {
+		return ""
+	}
+	for _, name := range []string{svc.ContainerName, svc.DockerName, svc.Name, svc.ID} {
+		if name != "" {
+			e = strings.ReplaceAll(e, strings.ToLower(name), "<service>")
+		}
+	}
+	e = urlPattern.ReplaceAllString(e, "<url>")
+	e = addrPattern.ReplaceAllString(e, "<addr>")
+	e = numberPattern.ReplaceAllString(e, "N")
+	if len(e) > maxErrorKey {
+		e = e[:maxErrorKey]
+	}
+	return e
+}
+
+// group is a candidate correlation during regroup
+type group struct {
+	class, key string
+	services   []string
+	since      time.Time
+}
+
+// regroup recomputes the correlations from the failing services. Every
+// service belongs to at most one correlation: larger groups claim their
+// services first, ties go to the more specific class.
+func (c *correlator) regroup(now time.Time) {
+	var groups []group
+	for _, class := range classOrder {
+		byKey := make(map[string][]string)
+		for id, f := range c.failing {
+			if k, ok := f.keys[class]; ok {
+				byKey[k] = append(byKey[k], id)
+			}
+		}
+		for k, ids := range byKey {
+			groups = append(groups, c.simultaneous(class, k, ids)...)
+		}
+	}
+	rank := make(map[string]int, len(classOrder))
+	for i, class := range classOrder {
+		rank[class] = i
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].services) != len(groups[j].services) {
+			return len(groups[i].services) > len(groups[j].services)
+		}
+		if groups[i].class != groups[j].class {
+			return rank[groups[i].class] < rank[groups[j].class]
+		}
+		return groups[i].key < groups[j].key
+	})
+
+	claimed := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		var ids []string
+		for _, id := range g.services {
+			if !claimed[id] {
+				ids = append(ids, id)
+			}
+		}
+		k := g.class + ":" + g.key
+		if len(ids) < c.opts.MinServices || seen[k] {
+			continue
+		}
+		for _, id := range ids {
+			claimed[id] = true
+		}
+		seen[k] = true
+		sort.Strings(ids)
+		c.apply(g, ids, now)
+	}
+
+	for k, corr := range c.active {
+		if seen[k] {
+			continue
+		}
+		delete(c.active, k)
+		corr.ResolvedAt = &now
+		corr.UpdatedAt = now
+		c.resolved = append(c.resolved, *corr)
+		if len(c.resolved) > keepResolvedCorrelations {
+			c.resolved = c.resolved[len(c.resolved)-keepResolvedCorrelations:]
+		}
+		c.publish(CorrelationResolved, *corr)
+	}
+}
+
+// simultaneous splits the services sharing a class key into groups whose
+// failures started within the window of each other
+func (c *correlator) simultaneous(class, key string, ids []string) []group {
+	sort.Slice(ids, func(i, j int) bool {
+		return c.failing[ids[i]].since.Before(c.failing[ids[j]].since)
+	})
+	var groups []group
+	var cur *group
+	var last time.Time
+	for _, id := range ids {
+		since := c.failing[id].since
+		if cur == nil || since.Sub(last) > c.opts.Window {
+			groups = append(groups, group{class: class, key: key, since: since})
+			cur = &groups[len(groups)-1]
+		}
+		cur.services = append(cur.services, id)
+		last = since
+	}
+	return groups
+}
+
+// apply creates or updates the active correlation of a group
+func (c *correlator) apply(g group, ids []string, now time.Time) {
+	causes := make(map[string]int)
+	for _, id := range ids {
+		causes[c.failing[id].rootCause]++
+	}
+	dominant := ""
+	for cause, n := range causes {
+		if n > causes[dominant] || (n == causes[dominant] && cause < dominant) {
+			dominant = cause
+		}
+	}
+
+	k := g.class + ":" + g.key
+	corr, ok := c.active[k]
+	if ok && sameServices(corr.Services, ids) && corr.RootCause == dominant {
+		return
+	}
+	kind := CorrelationUpdated
+	if !ok {
+		kind = CorrelationOpened
+		corr = &Correlation{ID: newCorrelationID(), Class: g.class, Key: g.key, StartedAt: g.since}
+		c.active[k] = corr
+	}
+	corr.Services = ids
+	corr.RootCause = dominant
+	corr.RootCauses = causes
+	corr.ProbableCause = probableCause(g.class, g.key, len(ids), dominant)
+	corr.UpdatedAt = now
+	c.publish(kind, *corr)
+}
+
+func sameServices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// probableCause describes the common cause of a group
+func probableCause(class, key string, n int, rootCause string) string {
+	switch class {
+	case ClassIngress:
+		return fmt.Sprintf("Public ingress %s (nginx) is failing: %d services behind it fail with %s", key, n, rootCause)
+	case ClassDockerHost:
+		return fmt.Sprintf("Internal host %s or its network is failing: %d containers unreachable (%s)", key, n, rootCause)
+	case ClassError:
+		return fmt.Sprintf("Shared dependency or upstream is failing: %d services report %q", n, key)
+	case ClassCategory:
+		return fmt.Sprintf("Category %s is failing: %d services down (%s)", key, n, rootCause)
+	}
+	return ""
+}
+
+// list returns the active correlations, largest first, and the resolved ones, newest first
+func (c *correlator) list() (active, resolved []Correlation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active = make([]Correlation, 0, len(c.active))
+	for _, corr := range c.active {
+		active = append(active, *corr)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if len(active[i].Services) != len(active[j].Services) {
+			return len(active[i].Services) > len(active[j].Services)
+		}
+		return active[i].StartedAt.Before(active[j].StartedAt)
+	})
+	resolved = make([]Correlation, 0, len(c.resolved))
+	for i := len(c.resolved) - 1; i >= 0; i-- {
+		resolved = append(resolved, c.resolved[i])
+	}
+	return active, resolved
+}
+
+// publish sends an event to every subscriber without blocking.
+// Must be called with c.mu held.
+func (c *correlator) publish(kind string, corr Correlation) {
+	ev := CorrelationEvent{Kind: kind, Correlation: corr}
+	for ch := range c.subs {
+		select {
+		case ch <- ev:
+		default:
+			delete(c.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func newCorrelationID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func failingService(id, status, dockerName string) models.Service {
+	return models.Service{
+		ID:         id,
+		Status:     status,
+		RootCause:  models.RootCauseContainerDown,
+		DockerName: dockerName,
+		LastError:  fmt.Sprintf("Internal health: dial tcp %s:8080: connect: connection refused", id),
+	}
+}
+
+func TestCorrelationGroupsByAnsweringHost(t *testing.T) {
+	c := newCorrelator(DefaultCorrelationOptions())
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		c.observe(failingService(fmt.Sprintf("gw%d", i), models.StatusUnhealthy, "host.docker.internal"), now)
+	}
+	// Reached by their own container names: no shared host
+	for i := 0; i < 2; i++ {
+		id := fmt.Sprintf("own%d", i)
+		c.observe(failingService(id, models.StatusCircuitOpen, id+"-app-1"), now)
+	}
+
+	active, _ := c.list()
+	if len(active) != 1 {
+		t.Fatalf("got %d correlations, want 1: %+v", len(active), active)
+	}
+	// The error class is larger, so the five share it; the host group lost its services
+	if got := active[0]; got.Class != ClassError || len(got.Services) != 5 {
+		t.Errorf("got %s %v", got.Class, got.Services)
+	}
+
+	// With different errors the shared host is what groups them
+	c = newCorrelator(DefaultCorrelationOptions())
+	for i, e := range []string{"timeout", "refused", "reset"} {
+		svc := failingService(fmt.Sprintf("gw%d", i), models.StatusUnhealthy, "host.docker.internal")
+		svc.LastError = e
+		c.observe(svc, now)
+	}
+	active, _ = c.list()
+	if len(active) != 1 || active[0].Class != ClassDockerHost || active[0].Key != "host.docker.internal" {
+		t.Fatalf("got %+v, want one docker_host correlation", active)
+	}
+}
+
+func TestCorrelationIgnoresDegraded(t *testing.T) {
+	c := newCorrelator(DefaultCorrelationOptions())
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		svc := failingService(fmt.Sprintf("svc%d", i), models.StatusDegraded, "host.docker.internal")
+		svc.Category = "tools"
+		c.observe(svc, now)
+	}
+	if active, _ := c.list(); len(active) != 0 {
+		t.Fatalf("got %+v, want degraded services left out", active)
+	}
+
+	// Going down joins them; degrading again resolves the outage
+	for i := 0; i < 3; i++ {
+		svc := failingService(fmt.Sprintf("svc%d", i), models.StatusUnhealthy, "host.docker.internal")
+		svc.Category = "tools"
+		c.observe(svc, now)
+	}
+	if active, _ := c.list(); len(active) != 1 {
+		t.Fatalf("got %d correlations, want 1", len(active))
+	}
+	c.observe(failingService("svc0", models.StatusDegraded, "host.docker.internal"), now)
+	active, resolved := c.list()
+	if len(active) != 0 || len(resolved) != 1 {
+		t.Errorf("got %d active and %d resolved, want the outage resolved", len(active), len(resolved))
+	}
+}
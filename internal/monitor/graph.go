@@ -0,0 +1,231 @@
+package monitor
+
+import (
+	"sort"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// GraphNode is a service in the dependency graph
+type GraphNode struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Category   string   `json:"category"`
+	Status     string   `json:"status"`
+	RootCause  string   `json:"root_cause,omitempty"`
+	Impacted   bool     `json:"impacted"`              // Failing because an upstream fails
+	ImpactedBy []string `json:"impacted_by,omitempty"` // The failing upstreams blamed for it
+	Dependents int      `json:"dependents"`            // Services declaring a dependency on it
+}
+
+// GraphEdge points from a service to a service it depends on
+type GraphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Missing bool   `json:"missing,omitempty"` // The dependency is not in the registry
+	Failing bool   `json:"failing"`           // The dependency is down
+}
+
+// Graph is the declared service dependency graph with current statuses
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph returns the dependency graph of every service
+func (m *Monitor) Graph() Graph {
+	services := m.registry.GetAll()
+	dependents := make(map[string]int)
+	for _, s := range services {
+		for _, dep := range s.DependsOn {
+			dependents[dep]++
+		}
+	}
+	byID := indexServices(services)
+
+	g := Graph{Nodes: make([]GraphNode, 0, len(services)), Edges: []GraphEdge{}}
+	for _, s := range services {
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:         s.ID,
+			Name:       s.Name,
+			Category:   s.Category,
+			Status:     s.Status,
+			RootCause:  s.RootCause,
+			Impacted:   len(s.ImpactedBy) > 0,
+			ImpactedBy: s.ImpactedBy,
+			Dependents: dependents[s.ID],
+		})
+		for _, dep := range s.DependsOn {
+			up, ok := byID[dep]
+			g.Edges = append(g.Edges, GraphEdge{
+				From:    s.ID,
+				To:      dep,
+				Missing: !ok,
+				Failing: ok && upstreamDown(up.Status),
+			})
+		}
+	}
+	return g
+}
+
+func indexServices(services []models.Service) map[string]*models.Service {
+	byID := make(map[string]*models.Service, len(services))
+	for i := range services {
+		byID[services[i].ID] = &services[i]
+	}
+	return byID
+}
+
+// upstreamDown reports whether a dependency in this status breaks its dependents
+func upstreamDown(status string) bool {
+	return status == models.StatusUnhealthy || status == models.StatusCircuitOpen
+}
+
+// impactedBy returns the failing upstreams a failing service is blamed on:
+// the failures reachable through failing dependencies that do not depend on
+// a failing service themselves. It is nil when svc is up or no dependency
+// is down. get returns a service by ID, nil when it is not in the registry.
+func impactedBy(svc *models.Service, get func(id string) *models.Service) []string {
+	if !failingStatus(svc.Status) {
+		return nil
+	}
+	failed := make(map[string]*models.Service)
+	queue := append([]string(nil), svc.DependsOn...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if failed[id] != nil || id == svc.ID {
+			continue
+		}
+		up := get(id)
+		if up == nil || !upstreamDown(up.Status) {
+			continue
+		}
+		failed[id] = up
+		queue = append(queue, up.DependsOn...)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	var roots []string
+	for id, up := range failed {
+		root := true
+		for _, dep := range up.DependsOn {
+			if failed[dep] != nil {
+				root = false
+				break
+			}
+		}
+		if root {
+			roots = append(roots, id)
+		}
+	}
+	if len(roots) == 0 {
+		// Every failing upstream waits on another one: a cycle
+		for id := range failed {
+			roots = append(roots, id)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// indexDependents rebuilds the reverse dependency index used by propagate.
+// It runs when services are added, removed or change their dependencies,
+// not on every check.
+func (m *Monitor) indexDependents() {
+	dependents := make(map[string][]string)
+	for _, s := range m.registry.GetAll() {
+		for _, dep := range s.DependsOn {
+			dependents[dep] = append(dependents[dep], s.ID)
+		}
+	}
+	m.graphMu.Lock()
+	m.dependents = dependents
+	m.graphMu.Unlock()
+}
+
+// propagate relabels id and every service depending on it, directly or not,
+// after id changed: failing services with a failing upstream get the
+// upstream root cause, the others get back the cause of their own check.
+// Dependents that are no longer impacted are checked right away. It returns
+// the record of id after relabeling.
+func (m *Monitor) propagate(id string) (models.Service, bool) {
+	m.graphMu.Lock()
+	defer m.graphMu.Unlock()
+
+	// Only id, its dependents and their upstreams are read from the registry
+	read := make(map[string]*models.Service)
+	get := func(sid string) *models.Service {
+		s, ok := read[sid]
+		if !ok {
+			if svc, found := m.registry.Get(sid); found {
+				s = &svc
+			}
+			read[sid] = s
+		}
+		return s
+	}
+	if get(id) == nil {
+		return models.Service{}, false
+	}
+
+	// Services reachable from id against the direction of the edges
+	affected := []string{id}
+	seen := map[string]bool{id: true}
+	for i := 0; i < len(affected); i++ {
+		for _, d := range m.dependents[affected[i]] {
+			if !seen[d] {
+				seen[d] = true
+				affected = append(affected, d)
+			}
+		}
+	}
+
+	var result models.Service
+	found := false
+	for _, sid := range affected {
+		svc := get(sid)
+		if svc == nil {
+			continue
+		}
+		roots := impactedBy(svc, get)
+		var released bool
+		snapshot, ok := m.registry.Update(sid, func(s *models.Service) bool {
+			cause := ""
+			if s.Diagnosis != nil {
+				cause = s.Diagnosis.RootCause
+			}
+			if len(roots) > 0 {
+				cause = models.RootCauseUpstream
+			}
+			if cause == s.RootCause && sameServices(roots, s.ImpactedBy) {
+				return false
+			}
+			released = len(s.ImpactedBy) > 0 && len(roots) == 0
+			s.ImpactedBy = roots
+			s.RootCause = cause
+			return true
+		})
+		if !ok {
+			continue
+		}
+		if sid == id {
+			result, found = snapshot, true
+		} else if released {
+			m.sched.update(snapshot)
+		}
+	}
+	return result, found
+}
+
+// dependencyRemoved relabels the services that depended on a removed service
+func (m *Monitor) dependencyRemoved(id string) {
+	m.graphMu.Lock()
+	dependents := append([]string(nil), m.dependents[id]...)
+	m.graphMu.Unlock()
+	for _, d := range dependents {
+		m.propagate(d)
+	}
+}
@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// graphService is a service with its own check's root cause and dependencies
+func graphService(id, status string, dependsOn ...string) models.Service {
+	cause := models.RootCauseNone
+	if failingStatus(status) {
+		cause = models.RootCauseAppError
+	}
+	return models.Service{
+		ID:        id,
+		Status:    status,
+		RootCause: cause,
+		Diagnosis: &models.Diagnosis{RootCause: cause},
+		DependsOn: dependsOn,
+	}
+}
+
+// labels returns "id:root_cause:impacted_by" for every service, ordered by ID
+func labels(r *models.Registry) string {
+	var out []string
+	for _, s := range r.GetAll() {
+		out = append(out, s.ID+":"+s.RootCause+":"+strings.Join(s.ImpactedBy, "+"))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestPropagate(t *testing.T) {
+	down, up := models.StatusUnhealthy, models.StatusHealthy
+	tests := []struct {
+		name     string
+		services []models.Service
+		changed  string // Service whose check triggers the propagation
+		want     string
+	}{
+		{
+			name: "chain",
+			services: []models.Service{
+				graphService("a", down, "b"),
+				graphService("b", down, "c"),
+				graphService("c", down),
+			},
+			changed: "c",
+			want:    "a:upstream:c b:upstream:c c:app_error:",
+		},
+		{
+			name: "chain with a healthy link",
+			services: []models.Service{
+				graphService("a", down, "b"),
+				graphService("b", up, "c"),
+				graphService("c", down),
+			},
+			changed: "c",
+			want:    "a:app_error: b:none: c:app_error:",
+		},
+		{
+			name: "degraded upstream does not impact",
+			services: []models.Service{
+				graphService("a", down, "b"),
+				graphService("b", models.StatusDegraded),
+			},
+			changed: "b",
+			want:    "a:app_error: b:app_error:",
+		},
+		{
+			name: "diamond",
+			services: []models.Service{
+				graphService("a", down, "b", "c"),
+				graphService("b", down, "d"),
+				graphService("c", models.StatusCircuitOpen, "d"),
+				graphService("d", down),
+			},
+			changed: "d",
+			want:    "a:upstream:d b:upstream:d c:upstream:d d:app_error:",
+		},
+		{
+			name: "diamond with one failing side",
+			services: []models.Service{
+				graphService("a", down, "b", "c"),
+				graphService("b", down),
+				graphService("c", up, "d"),
+				graphService("d", up),
+			},
+			changed: "b",
+			want:    "a:upstream:b b:app_error: c:none: d:none:",
+		},
+		{
+			name: "cycle",
+			services: []models.Service{
+				graphService("a", down, "b"),
+				graphService("b", down, "a"),
+			},
+			changed: "a",
+			want:    "a:upstream:b b:upstream:a",
+		},
+		{
+			name: "downstream of a cycle",
+			services: []models.Service{
+				graphService("a", down, "b"),
+				graphService("b", down, "a"),
+				graphService("x", down, "a"),
+			},
+			changed: "a",
+			want:    "a:upstream:b b:upstream:a x:upstream:a+b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := models.NewRegistry()
+			for _, s := range tt.services {
+				registry.Add(s)
+			}
+			m := NewMonitor(registry, nil)
+			m.indexDependents()
+			if _, ok := m.propagate(tt.changed); !ok {
+				t.Fatalf("%s not found", tt.changed)
+			}
+			if got := labels(registry); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropagateRecovery(t *testing.T) {
+	registry := models.NewRegistry()
+	registry.Add(graphService("a", models.StatusUnhealthy, "b"))
+	registry.Add(graphService("b", models.StatusUnhealthy, "c"))
+	registry.Add(graphService("c", models.StatusUnhealthy))
+	m := NewMonitor(registry, nil)
+	m.indexDependents()
+	m.propagate("c")
+
+	// The root recovers: its dependents get back the cause of their own check
+	registry.Update("c", func(s *models.Service) bool {
+		s.Status, s.RootCause, s.Diagnosis = models.StatusHealthy, models.RootCauseNone, &models.Diagnosis{RootCause: models.RootCauseNone}
+		return true
+	})
+	m.propagate("c")
+	if got, want := labels(registry), "a:upstream:b b:app_error: c:none:"; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	// Removing the failing upstream releases its dependent
+	registry.Remove("b")
+	m.indexDependents()
+	m.dependencyRemoved("b")
+	if got, want := labels(registry), "a:app_error: c:none:"; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,15 @@
+package monitor
+
+import (
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+)
+
+// SetIncidents sets the tracker that derives incidents from check results. Call before Start.
+func (m *Monitor) SetIncidents(t *incident.Tracker) {
+	m.incidents = t
+}
+
+// Incidents returns the incident tracker (may be nil)
+func (m *Monitor) Incidents() *incident.Tracker {
+	return m.incidents
+}
@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// ErrHistoryDisabled is returned by reports that need the history store
+var ErrHistoryDisabled = errors.New("history is disabled")
+
+// LatencyPhases are the phase names reported by Latency, in request order
+var LatencyPhases = []string{"dns", "connect", "tls", "ttfb", "total"}
+
+// Percentiles summarises one latency phase in milliseconds
+type Percentiles struct {
+	P50 int64 `json:"p50_ms"`
+	P95 int64 `json:"p95_ms"`
+	P99 int64 `json:"p99_ms"`
+	Max int64 `json:"max_ms"`
+}
+
+// LatencyReport is the per-phase latency of the passing health requests of a service.
+// Only raw checks carry timings, so windows reaching past the history raw window
+// report fewer samples. Requests on a reused connection skip DNS, connect and TLS
+// and only count towards ttfb and total.
+type LatencyReport struct {
+	ServiceID string                 `json:"id"`
+	From      time.Time              `json:"from"`
+	To        time.Time              `json:"to"`
+	Samples   int                    `json:"samples"`
+	Phases    map[string]Percentiles `json:"phases"`
+}
+
+// Latency computes p50/p95/p99 per phase over the window ending now
+func (m *Monitor) Latency(id string, window time.Duration) (LatencyReport, error) {
+	to := time.Now()
+	report := LatencyReport{
+		ServiceID: id,
+		From:      to.Add(-window),
+		To:        to,
+		Phases:    make(map[string]Percentiles, len(LatencyPhases)),
+	}
+	if m.history == nil {
+		return report, ErrHistoryDisabled
+	}
+
+	records, err := m.history.Query(id, report.From, to, 0)
+	if err != nil {
+		return report, err
+	}
+
+	samples := make(map[string][]int64, len(LatencyPhases))
+	for _, rec := range records {
+		t := rec.Timings
+		if t == nil {
+			continue
+		}
+		report.Samples++
+		if !t.Reused {
+			samples["dns"] = append(samples["dns"], t.DNSMs)
+			samples["connect"] = append(samples["connect"], t.ConnectMs)
+			samples["tls"] = append(samples["tls"], t.TLSMs)
+		}
+		samples["ttfb"] = append(samples["ttfb"], t.TTFBMs)
+		samples["total"] = append(samples["total"], t.TotalMs)
+	}
+	for _, phase := range LatencyPhases {
+		report.Phases[phase] = percentiles(samples[phase])
+	}
+	return report, nil
+}
+
+// percentiles uses the nearest-rank method
+func percentiles(values []int64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := func(p float64) int64 {
+		i := int(math.Ceil(p/100*float64(len(values)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return values[i]
+	}
+	return Percentiles{
+		P50: rank(50),
+		P95: rank(95),
+		P99: rank(99),
+		Max: values[len(values)-1],
+	}
+}
+
+// latencyMs is the request latency used for the response histogram:
+// the passing request's total when known, else the check's wall time
+func latencyMs(timings *models.PhaseTimings, wallMs int64) int64 {
+	if timings != nil {
+		return timings.TotalMs
+	}
+	return wallMs
+}
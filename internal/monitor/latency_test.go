@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestLatencySkipsReusedConnectionPhases(t *testing.T) {
+	store, err := history.NewStore(t.TempDir(), history.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, timings := range []models.PhaseTimings{
+		{DNSMs: 20, ConnectMs: 10, TLSMs: 30, TTFBMs: 80, TotalMs: 90},
+		{TTFBMs: 15, TotalMs: 20, Reused: true},
+		{TTFBMs: 16, TotalMs: 21, Reused: true},
+	} {
+		timings := timings
+		store.Append(history.Record{ServiceID: "svc", Timestamp: now.Add(time.Duration(i-3) * time.Minute), Status: models.StatusHealthy, Timings: &timings})
+	}
+
+	m := &Monitor{history: store}
+	report, err := m.Latency("svc", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Samples != 3 {
+		t.Fatalf("got %d samples, want 3", report.Samples)
+	}
+	// Only the fresh connection counts for the connection phases
+	for phase, want := range map[string]int64{"dns": 20, "connect": 10, "tls": 30} {
+		if got := report.Phases[phase]; got.P50 != want || got.Max != want {
+			t.Errorf("got %s %+v, want %d", phase, got, want)
+		}
+	}
+	if got := report.Phases["total"]; got.P50 != 21 || got.Max != 90 {
+		t.Errorf("got total %+v", got)
+	}
+}
@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/maintenance"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// SetMaintenance sets the store of maintenance windows and silences. Call before Start.
+func (m *Monitor) SetMaintenance(s *maintenance.Store) {
+	m.maintenance = s
+}
+
+// InMaintenance returns the active window covering svc, or nil
+func (m *Monitor) InMaintenance(svc models.Service) *maintenance.Window {
+	return m.maintenance.Match(&svc, time.Now())
+}
+
+// MaintenanceChanged checks right away every service whose maintenance state
+// no longer matches the store, so new or deleted windows show up without
+// waiting for the next scheduled check.
+func (m *Monitor) MaintenanceChanged() {
+	now := time.Now()
+	for _, svc := range m.registry.GetAll() {
+		id := ""
+		if w := m.maintenance.Match(&svc, now); w != nil {
+			id = w.ID
+		}
+		if id != svc.Maintenance {
+			m.sched.update(svc)
+		}
+	}
+}
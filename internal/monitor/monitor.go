@@ -1,208 +1,469 @@
 package monitor
 
 import (
-	"fmt"
 	"log"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/baditaflorin/go_services_dashboard/internal/checker"
+	"github.com/baditaflorin/go_services_dashboard/internal/compliance"
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+	"github.com/baditaflorin/go_services_dashboard/internal/maintenance"
+	"github.com/baditaflorin/go_services_dashboard/internal/metrics"
 	"github.com/baditaflorin/go_services_dashboard/internal/models"
+	"github.com/baditaflorin/go_services_dashboard/internal/pause"
 )
 
-// ServiceUpdate represents a real-time update for a service
-type ServiceUpdate struct {
-	ServiceID  string `json:"id"`
-	Status     string `json:"status"`
-	TestStatus string `json:"test_status,omitempty"`
-	TestError  string `json:"test_error,omitempty"`
-	LastError  string `json:"last_error,omitempty"`
-	ResponseMs int64  `json:"response_ms"`
+// Transition describes a status change (or circuit breaker trip) seen by the monitor
+type Transition struct {
+	Service        models.Service // Snapshot taken after the check
+	From           string
+	To             string
+	Error          string
+	CircuitTripped bool
+	At             time.Time
+	Silenced       bool // An active silence covers the service: nothing should be sent
+	Impacted       bool // Failing because an upstream fails: the upstream's alert covers it
 }
 
 // Monitor handles background health checking
 type Monitor struct {
-	registry  *models.Registry
-	client    *http.Client
-	interval  time.Duration
-	clients   map[chan ServiceUpdate]bool
-	clientsMu sync.RWMutex
+	registry *models.Registry
+	history  *history.Store
+	client   *http.Client
+	sched    *scheduler
+
+	listenersMu sync.RWMutex
+	listeners   []func(Transition)
+
+	statsMu      sync.Mutex
+	checkHist    *metrics.Histogram
+	responseHist map[string]*metrics.Histogram
+	complianceBy map[string]compliance.ComplianceReport
+	complianceMu sync.Mutex // Serialises ScanCompliance
+
+	breaker     BreakerOptions
+	maintenance *maintenance.Store
+	pauses      *pause.Store
+	incidents   *incident.Tracker
+	correlator  *correlator
+	graphMu     sync.Mutex          // Serialises dependency propagation
+	dependents  map[string][]string // Reverse dependency index, guarded by graphMu
+
+	slaMu      sync.Mutex
+	defaultSLO float64
+	slaCache   *SLAReport
 }
 
-// NewMonitor creates a new health monitor
-func NewMonitor(r *models.Registry) *Monitor {
-	return &Monitor{
+// NewMonitor creates a new health monitor.
+// Check results are persisted to store when it is non-nil.
+func NewMonitor(r *models.Registry, store *history.Store) *Monitor {
+	m := &Monitor{
 		registry: r,
+		history:  store,
 		client: &http.Client{
-			Timeout: 5 * time.Second,
+			Timeout: DefaultTimeout,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				return nil // Follow redirects
 			},
 		},
-		interval: 30 * time.Second,
-		clients:  make(map[chan ServiceUpdate]bool),
+		defaultSLO:   DefaultSLO,
+		breaker:      DefaultBreakerOptions(),
+		correlator:   newCorrelator(DefaultCorrelationOptions()),
+		checkHist:    metrics.NewHistogram([]float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60}),
+		responseHist: make(map[string]*metrics.Histogram),
+		complianceBy: make(map[string]compliance.ComplianceReport),
+	}
+	m.sched = newScheduler(m.runCheck)
+	return m
+}
+
+// SetWorkers sets the number of checks run in parallel. Call before Start.
+func (m *Monitor) SetWorkers(n int) {
+	if n > 0 {
+		m.sched.workers = n
 	}
 }
 
-// Subscribe returns a channel for real-time updates
-func (m *Monitor) Subscribe() chan ServiceUpdate {
-	ch := make(chan ServiceUpdate, 50)
-	m.clientsMu.Lock()
-	m.clients[ch] = true
-	m.clientsMu.Unlock()
-	return ch
+// SetInterval sets the check interval of services without their own. Call before Start.
+func (m *Monitor) SetInterval(d time.Duration) {
+	if d > 0 {
+		m.sched.interval = d
+	}
 }
 
-// Unsubscribe removes a client listener
-func (m *Monitor) Unsubscribe(ch chan ServiceUpdate) {
-	m.clientsMu.Lock()
-	if _, ok := m.clients[ch]; ok {
-		delete(m.clients, ch)
-		close(ch)
+// SetJitter sets the fraction of the interval by which checks are randomly
+// moved to spread the load (0 disables it). Call before Start.
+func (m *Monitor) SetJitter(f float64) {
+	if f >= 0 && f < 1 {
+		m.sched.jitter = f
 	}
-	m.clientsMu.Unlock()
 }
 
-func (m *Monitor) broadcast(update ServiceUpdate) {
-	m.clientsMu.RLock()
-	defer m.clientsMu.RUnlock()
-	for ch := range m.clients {
-		select {
-		case ch <- update:
-		default:
-			// Skip slow clients to prevent blocking
-		}
+// SetTimeout sets the request timeout of services without their own. Call before Start.
+func (m *Monitor) SetTimeout(d time.Duration) {
+	if d > 0 {
+		m.sched.timeout = d
+		m.client.Timeout = d
 	}
 }
 
-// Start begins the monitoring loop
+// History returns the check history store (may be nil)
+func (m *Monitor) History() *history.Store {
+	return m.history
+}
+
+// OnTransition registers a callback invoked after every status transition.
+// Callbacks run on the checking goroutine and must not block.
+func (m *Monitor) OnTransition(fn func(Transition)) {
+	m.listenersMu.Lock()
+	m.listeners = append(m.listeners, fn)
+	m.listenersMu.Unlock()
+}
+
+func (m *Monitor) notifyTransition(t Transition) {
+	m.listenersMu.RLock()
+	defer m.listenersMu.RUnlock()
+	for _, fn := range m.listeners {
+		fn(t)
+	}
+}
+
+// Start resolves incidents of services removed while the dashboard was down,
+// schedules every service, spreading the first checks over one interval,
+// follows registry changes and runs the scheduler. It does not return.
 func (m *Monitor) Start() {
-	// Initial check
-	m.CheckAll()
+	events, _ := m.registry.Subscribe()
+	services, version := m.registry.Snapshot()
+	m.incidents.Reconcile(services, time.Now())
+	m.indexDependents()
+	for _, svc := range services {
+		m.sched.add(svc, true)
+	}
+	go m.watch(events, version)
+	log.Printf("Scheduling %d services (interval %s, %d workers)", len(services), m.sched.interval, m.sched.workers)
+	m.sched.start()
+}
 
-	ticker := time.NewTicker(m.interval)
-	for range ticker.C {
-		m.CheckAll()
+// watch keeps the scheduler in line with the registry: added services are
+// checked right away, reconfigured ones re-read their settings and state of
+// removed ones is dropped. Events already part of the snapshot are skipped.
+func (m *Monitor) watch(events <-chan models.Event, since uint64) {
+	for ev := range events {
+		if ev.Version <= since {
+			continue
+		}
+		switch ev.Kind {
+		case models.EventAdded:
+			if len(ev.Service.DependsOn) > 0 {
+				m.indexDependents()
+			}
+			m.sched.add(ev.Service, false)
+		case models.EventReconfigured:
+			m.sched.update(ev.Service)
+			if !sameServices(ev.Service.DependsOn, ev.Previous.DependsOn) {
+				m.indexDependents()
+				m.propagate(ev.Service.ID)
+			}
+		case models.EventRemoved:
+			m.sched.remove(ev.Service.ID)
+			if len(ev.Service.DependsOn) > 0 {
+				m.indexDependents()
+			}
+			m.incidents.Forget(ev.Service.ID, time.Now())
+			m.correlator.forget(ev.Service.ID, time.Now())
+			m.dependencyRemoved(ev.Service.ID)
+			m.statsMu.Lock()
+			delete(m.responseHist, ev.Service.ID)
+			delete(m.complianceBy, ev.Service.ID)
+			m.statsMu.Unlock()
+		}
 	}
+	log.Printf("Registry event stream closed, scheduler no longer follows changes")
 }
 
+// CheckAll makes every service due now. Checks already queued or running are not repeated.
 func (m *Monitor) CheckAll() {
-	services := m.registry.GetAll()
-	log.Printf("Starting health check cycle for %d services...", len(services))
-
-	// Worker Pool: Limit concurrent checks to prevent network exhaustion
-	numWorkers := 10
-	jobs := make(chan *models.Service, len(services))
-	var wg sync.WaitGroup
-
-	// Start workers
-	for w := 0; w < numWorkers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for svc := range jobs {
-				m.CheckService(svc)
-			}
-		}()
+	m.sched.triggerAll()
+}
+
+// runCheck is the scheduler's job: one check plus its duration
+func (m *Monitor) runCheck(id string, timeout time.Duration) {
+	start := time.Now()
+	m.CheckService(id, timeout)
+	m.checkHist.Observe(time.Since(start).Seconds())
+}
+
+// SchedulerStats returns statistics about the check scheduler
+func (m *Monitor) SchedulerStats() SchedulerStats {
+	return m.sched.stats()
+}
+
+// Schedules returns the scheduling state of every service, next due first
+func (m *Monitor) Schedules() []ServiceSchedule {
+	return m.sched.schedules()
+}
+
+// CheckHistogram returns the distribution of check durations in seconds, retries included
+func (m *Monitor) CheckHistogram() *metrics.Histogram {
+	return m.checkHist
+}
+
+// ResponseHistogram returns the response time distribution of a service in seconds
+func (m *Monitor) ResponseHistogram(id string) *metrics.Histogram {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	return m.responseHist[id]
+}
+
+func (m *Monitor) observeResponse(id string, ms int64) {
+	m.statsMu.Lock()
+	h, ok := m.responseHist[id]
+	if !ok {
+		h = metrics.NewHistogram(metrics.DefaultLatencyBuckets)
+		m.responseHist[id] = h
 	}
+	m.statsMu.Unlock()
+	h.Observe(float64(ms) / 1000)
+}
 
-	// Enqueue jobs
-	for _, svc := range services {
-		jobs <- svc
+// RecordCompliance stores the latest compliance reports
+func (m *Monitor) RecordCompliance(reports []compliance.ComplianceReport) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	for _, r := range reports {
+		m.complianceBy[r.ServiceID] = r
 	}
-	close(jobs)
+}
 
-	wg.Wait()
-	log.Printf("Health check cycle completed.")
+// Compliance returns the last compliance report of a service
+func (m *Monitor) Compliance(id string) (compliance.ComplianceReport, bool) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	r, ok := m.complianceBy[id]
+	return r, ok
 }
 
-func (m *Monitor) CheckService(svc *models.Service) {
-	m.registry.Mu.Lock()
-	// Circuit Breaker Check
-	if !svc.CircuitOpenUntil.IsZero() && time.Now().Before(svc.CircuitOpenUntil) {
-		svc.Status = "unhealthy"
-		svc.LastError = fmt.Sprintf("Circuit Open (cooling down until %s)", svc.CircuitOpenUntil.Format("15:04:05"))
-		m.registry.Mu.Unlock()
+// CheckService checks one service and stores the result in the registry.
+// timeout bounds each request; the scheduler passes the service's own or the default.
+func (m *Monitor) CheckService(id string, timeout time.Duration) {
+	var (
+		window     *maintenance.Window
+		silence    *maintenance.Window
+		unsilenced bool // A silence ended since the last check
+		run, probe bool
+		pausedFrom string
+		pausedNow  bool
+	)
+	svc, ok := m.registry.Update(id, func(s *models.Service) bool {
+		now := time.Now()
+		// Paused services are not checked at all
+		prevStatus := s.Status
+		if changed, paused := m.applyPause(s, now); paused {
+			pausedNow, pausedFrom = true, prevStatus
+			return changed
+		}
+		// Maintenance: checks keep running (results are kept for diagnosis) but
+		// the breaker is left alone and the service reports maintenance
+		window = m.maintenance.Match(s, now)
+		if window != nil && window.Kind == maintenance.KindSilence {
+			silence, window = window, nil
+		}
+		if window != nil {
+			run = true
+			return false
+		}
+		// A silence only mutes alerts: status, breaker and SLA carry on as usual
+		changed := false
+		if s.Status != models.StatusMaintenance && s.Maintenance != silenceID(silence) {
+			unsilenced = silence == nil
+			s.Maintenance = silenceID(silence)
+			changed = true
+		}
+		// Circuit Breaker: skip while open, send a single probe once the cool-down is over
+		prev := s.Breaker.State
+		run, probe = m.admit(&s.Breaker, now)
+		return changed || s.Breaker.State != prev
+	})
+	if !ok {
+		return // Removed meanwhile
+	}
+	if pausedNow {
+		if pausedFrom != models.StatusPaused {
+			m.incidents.Observe(svc, time.Now())
+			m.correlator.observe(svc, time.Now())
+			m.notifyTransition(Transition{Service: svc, From: pausedFrom, To: svc.Status, At: time.Now()})
+		}
+		return
+	}
+	if !run {
+		// Keep one sample per slot while the breaker is open, otherwise a
+		// long outage would weigh no more than its probes
+		m.record(id, time.Now(), models.StatusCircuitOpen, checker.CheckServiceResult{
+			LastError: svc.Breaker.Reason,
+			Diagnosis: models.Diagnosis{RootCause: svc.RootCause},
+		})
+		if unsilenced {
+			// The trip was muted by the silence; report it now
+			m.notifyTransition(Transition{Service: svc, From: models.StatusMaintenance, To: svc.Status,
+				Error: svc.Breaker.Reason, CircuitTripped: true, At: time.Now()})
+		}
 		return
 	}
-	m.registry.Mu.Unlock()
 
-	// Retry Logic: Try up to 3 times (0s, 1s, 2s wait)
+	client := m.client
+	if timeout > 0 && timeout != client.Timeout {
+		c := *m.client
+		c.Timeout = timeout
+		client = &c
+	}
+
+	// Retry Logic: Try up to 3 times (0s, 1s, 2s wait); a half-open probe gets one try
+	attempts := 3
+	if probe {
+		attempts = 1
+	}
 	var result checker.CheckServiceResult
-	for attempt := 0; attempt < 3; attempt++ {
-		result = checker.CheckService(m.client, svc)
-		if result.Status == "healthy" {
+	for attempt := 0; attempt < attempts; attempt++ {
+		result = checker.CheckService(client, &svc)
+		if models.StatusUp(result.Status) {
 			break
 		}
-		if attempt < 2 {
+		if attempt < attempts-1 {
 			time.Sleep(time.Duration(attempt+1) * time.Second)
 		}
 	}
 
-	m.registry.Mu.Lock()
-	svc.LastChecked = time.Now()
-	svc.ResponseMs = result.ResponseMs
-	svc.Status = result.Status
-	svc.HealthStatus = result.HealthStatus
-	svc.ExampleStatus = result.ExampleStatus
-	svc.LastError = result.LastError
-	if result.Version != "" {
-		svc.Version = result.Version
-	}
+	var prevStatus string
+	circuitTripped, applied := false, false
+	snapshot, ok := m.registry.Update(id, func(s *models.Service) bool {
+		if m.pauses.Match(s, time.Now()) != nil {
+			return false // Paused while checking; the next run reports it
+		}
+		applied = true
+		prevStatus = s.Status
+		s.LastChecked = time.Now()
+		s.ResponseMs = result.ResponseMs
+		s.Timings = result.Timings
+		s.Status = result.Status
+		s.HealthStatus = result.HealthStatus
+		s.ExampleStatus = result.ExampleStatus
+		s.LastError = result.LastError
+		s.RootCause = result.Diagnosis.RootCause
+		if len(s.ImpactedBy) > 0 && failingStatus(s.Status) {
+			s.RootCause = models.RootCauseUpstream // Kept until propagate says otherwise
+		}
+		s.Diagnosis = &result.Diagnosis
+		s.Paused = nil
+		if result.Version != "" {
+			s.Version = result.Version
+		}
+		if result.DockerName != "" {
+			s.DockerName = result.DockerName
+		}
 
-	// Circuit Breaker State Update
-	if result.Status != "healthy" {
-		svc.ConsecutiveFailures++
-		if svc.ConsecutiveFailures >= 5 {
-			svc.CircuitOpenUntil = time.Now().Add(5 * time.Minute)
-			svc.LastError = "Circuit Breaker Tripped (5 failing checks)"
+		// Circuit Breaker State Update
+		if window != nil {
+			s.Status = models.StatusMaintenance
+			s.Maintenance = window.ID
+		} else {
+			s.Maintenance = silenceID(silence)
+			circuitTripped = m.settle(&s.Breaker, models.StatusUp(result.Status), result.LastError, s.LastChecked)
+			if !s.Breaker.Closed() {
+				s.Status = models.StatusCircuitOpen
+			}
 		}
-	} else {
-		svc.ConsecutiveFailures = 0
-		svc.CircuitOpenUntil = time.Time{}
+
+		// Track health history (last 5 checks)
+		s.HealthHistory = append(s.HealthHistory, result.Status)
+		if len(s.HealthHistory) > 5 {
+			s.HealthHistory = s.HealthHistory[1:]
+		}
+		return true
+	})
+	if !ok || !applied {
+		return
 	}
 
-	// Track health history (last 5 checks)
-	svc.HealthHistory = append(svc.HealthHistory, result.Status)
-	if len(svc.HealthHistory) > 5 {
-		svc.HealthHistory = svc.HealthHistory[1:]
+	recorded := result.Status
+	if window != nil {
+		recorded = models.StatusMaintenance
+	}
+	m.record(id, snapshot.LastChecked, recorded, result)
+	m.observeResponse(id, latencyMs(result.Timings, result.ResponseMs))
+	if relabeled, ok := m.propagate(id); ok {
+		snapshot = relabeled
 	}
-	m.registry.Mu.Unlock()
+	m.incidents.Observe(snapshot, snapshot.LastChecked)
+	m.correlator.observe(snapshot, snapshot.LastChecked)
 
-	// Broadcast update
-	m.broadcast(ServiceUpdate{
-		ServiceID:  svc.ID,
-		Status:     svc.Status,
-		LastError:  svc.LastError,
-		ResponseMs: svc.ResponseMs,
+	// The end of a silence is reported like the end of a maintenance window,
+	// so a service still down alerts and one that recovered meanwhile says so
+	from := prevStatus
+	if unsilenced {
+		from = models.StatusMaintenance
+	}
+	if from != snapshot.Status || circuitTripped {
+		m.notifyTransition(Transition{
+			Service:        snapshot,
+			From:           from,
+			To:             snapshot.Status,
+			Error:          snapshot.LastError,
+			CircuitTripped: circuitTripped,
+			At:             snapshot.LastChecked,
+			Silenced:       silence != nil,
+			Impacted:       len(snapshot.ImpactedBy) > 0,
+		})
+	}
+}
+
+// silenceID returns the ID of w, "" when nil
+func silenceID(w *maintenance.Window) string {
+	if w == nil {
+		return ""
+	}
+	return w.ID
+}
+
+// record persists a check result to the history store under status, which
+// differs from the result's status during maintenance
+func (m *Monitor) record(id string, at time.Time, status string, result checker.CheckServiceResult) {
+	if m.history == nil {
+		return
+	}
+	err := m.history.Append(history.Record{
+		ServiceID:     id,
+		Timestamp:     at,
+		Status:        status,
+		HealthStatus:  result.HealthStatus,
+		ExampleStatus: result.ExampleStatus,
+		ResponseMs:    result.ResponseMs,
+		Error:         result.LastError,
+		Version:       result.Version,
+		RootCause:     result.Diagnosis.RootCause,
+		Timings:       result.Timings,
 	})
+	if err != nil {
+		log.Printf("Failed to record history for %s: %v", id, err)
+	}
 }
 
 // TestActiveLink tests if the service's ExampleURL is actually working
 func (m *Monitor) TestActiveLink(id string) (string, string, error) {
-	m.registry.Mu.RLock()
-	svc, exists := m.registry.Services[id]
-	m.registry.Mu.RUnlock()
-
+	svc, exists := m.registry.Get(id)
 	if !exists {
 		return "", "", nil
 	}
 
-	result := checker.TestActiveLink(m.client, svc)
-
-	m.registry.Mu.Lock()
-	svc.TestStatus = result.Status
-	svc.TestError = result.Error
-	m.registry.Mu.Unlock()
+	result := checker.TestActiveLink(m.client, &svc)
 
-	// Broadcast update including test result
-	m.broadcast(ServiceUpdate{
-		ServiceID:  svc.ID,
-		Status:     svc.Status,
-		TestStatus: result.Status,
-		TestError:  result.Error,
-		ResponseMs: svc.ResponseMs,
+	m.registry.Update(id, func(s *models.Service) bool {
+		s.TestStatus = result.Status
+		s.TestError = result.Error
+		s.LastTested = time.Now()
+		return true
 	})
 
 	return result.Status, result.Error, nil
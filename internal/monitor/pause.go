@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+	"github.com/baditaflorin/go_services_dashboard/internal/pause"
+)
+
+// ErrPausesDisabled is returned by Pause and Resume when no pause store is set
+var ErrPausesDisabled = errors.New("pausing not configured")
+
+// SetPauses sets the store of paused services and categories. Call before Start.
+func (m *Monitor) SetPauses(s *pause.Store) {
+	m.pauses = s
+}
+
+// Pauses returns the pauses in effect
+func (m *Monitor) Pauses() []models.Pause {
+	if m.pauses == nil {
+		return []models.Pause{}
+	}
+	return m.pauses.List(time.Now())
+}
+
+// Pause stops checks of a service or category until resumed or p.Until.
+// Affected services show the paused status right away.
+func (m *Monitor) Pause(p models.Pause) error {
+	if m.pauses == nil {
+		return ErrPausesDisabled
+	}
+	if err := m.pauses.Pause(p, time.Now()); err != nil {
+		return err
+	}
+	m.recheckScope(p.Scope, p.Target)
+	return nil
+}
+
+// Resume lifts the pause of a service or category and checks the affected
+// services right away. A service stays paused while its category is.
+func (m *Monitor) Resume(scope, target string) (models.Pause, error) {
+	if m.pauses == nil {
+		return models.Pause{}, ErrPausesDisabled
+	}
+	p, err := m.pauses.Resume(scope, target, time.Now())
+	if err != nil {
+		return models.Pause{}, err
+	}
+	m.recheckScope(scope, target)
+	return p, nil
+}
+
+// recheckScope makes the services selected by a pause scope due now
+func (m *Monitor) recheckScope(scope, target string) {
+	for _, svc := range m.registry.GetAll() {
+		if (scope == models.PauseService && svc.ID == target) ||
+			(scope == models.PauseCategory && svc.Category == target) {
+			m.sched.update(svc)
+		}
+	}
+}
+
+// applyPause puts s into the paused status when a pause covers it. It
+// reports whether s changed and whether the check must be skipped.
+func (m *Monitor) applyPause(s *models.Service, now time.Time) (changed, paused bool) {
+	p := m.pauses.Match(s, now)
+	if p == nil {
+		return false, false
+	}
+	changed = s.Status != models.StatusPaused || s.Paused == nil ||
+		s.Paused.Scope != p.Scope || !s.Paused.PausedAt.Equal(p.PausedAt)
+	s.Status = models.StatusPaused
+	s.Paused = p
+	return changed, true
+}
@@ -0,0 +1,398 @@
+package monitor
+
+import (
+	"container/heap"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Scheduler defaults, overridable with the Monitor setters
+const (
+	DefaultInterval = 30 * time.Second
+	DefaultWorkers  = 10
+	DefaultJitter   = 0.1 // Fraction of the interval added or removed at random
+	DefaultTimeout  = 5 * time.Second
+)
+
+// SchedulerStats describes the check scheduler
+type SchedulerStats struct {
+	Workers     int             `json:"workers"`
+	BusyWorkers int             `json:"busy_workers"`
+	Scheduled   int             `json:"scheduled"` // Services known to the scheduler
+	Queued      int             `json:"queued"`    // Checks that are due and waiting for a worker
+	Checks      uint64          `json:"checks"`
+	Overruns    uint64          `json:"overruns"`
+	LastOverrun *Overrun        `json:"last_overrun,omitempty"`
+	MaxLag      models.Duration `json:"max_lag"`     // Largest start delay over the last window
+	Utilisation float64         `json:"utilisation"` // Busy worker time / (workers * window) over the last window
+	Window      models.Duration `json:"window"`
+}
+
+// Overrun is a check that started a whole interval late or ran longer than its interval
+type Overrun struct {
+	ServiceID string          `json:"id"`
+	Lag       models.Duration `json:"lag"`
+	Duration  models.Duration `json:"duration"`
+	Interval  models.Duration `json:"interval"`
+	At        time.Time       `json:"at"`
+}
+
+// ServiceSchedule is the scheduling state of one service
+type ServiceSchedule struct {
+	ID           string          `json:"id"`
+	Interval     models.Duration `json:"interval"`
+	Timeout      models.Duration `json:"timeout"`
+	Priority     int             `json:"priority"`
+	NextDue      time.Time       `json:"next_due"`
+	Running      bool            `json:"running"`
+	LastStart    time.Time       `json:"last_start,omitempty"`
+	LastDuration models.Duration `json:"last_duration"`
+	LastLag      models.Duration `json:"last_lag"`
+	Overruns     uint64          `json:"overruns"`
+}
+
+// schedEntry is one service in the scheduler.
+// It sits in the waiting heap until due, then in the ready heap until a worker takes it.
+type schedEntry struct {
+	ServiceSchedule
+	index int // Position in the heap holding it, -1 while running or removed
+	ready bool
+	gone  bool
+}
+
+// waitHeap orders entries by due time
+type waitHeap []*schedEntry
+
+func (h waitHeap) Len() int           { return len(h) }
+func (h waitHeap) Less(i, j int) bool { return h[i].NextDue.Before(h[j].NextDue) }
+func (h waitHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i]; h[i].index = i; h[j].index = j }
+func (h *waitHeap) Push(x interface{}) {
+	e := x.(*schedEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *waitHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	e.index = -1
+	return e
+}
+
+// readyHeap orders due entries by priority, then by how long they have been due
+type readyHeap struct{ waitHeap }
+
+func (h readyHeap) Less(i, j int) bool {
+	if h.waitHeap[i].Priority != h.waitHeap[j].Priority {
+		return h.waitHeap[i].Priority > h.waitHeap[j].Priority
+	}
+	return h.waitHeap.Less(i, j)
+}
+
+// scheduler runs each service on its own interval with a bounded worker pool
+type scheduler struct {
+	run      func(id string, timeout time.Duration)
+	interval time.Duration
+	timeout  time.Duration
+	jitter   float64
+	workers  int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	entries map[string]*schedEntry
+	running map[string]*schedEntry // Entries whose check runs, removed ones included
+	waiting waitHeap
+	ready   readyHeap
+	wake    chan struct{}
+
+	busy        int
+	busyTime    time.Duration
+	checks      uint64
+	overruns    uint64
+	lastOverrun *Overrun
+	maxLag      time.Duration
+	windowLag   time.Duration
+	utilisation float64
+	windowStart time.Time
+}
+
+func newScheduler(run func(id string, timeout time.Duration)) *scheduler {
+	s := &scheduler{
+		run:         run,
+		interval:    DefaultInterval,
+		timeout:     DefaultTimeout,
+		jitter:      DefaultJitter,
+		workers:     DefaultWorkers,
+		entries:     make(map[string]*schedEntry),
+		running:     make(map[string]*schedEntry),
+		wake:        make(chan struct{}, 1),
+		windowStart: time.Now(),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// settings returns the interval, timeout and priority of a service, falling back to defaults
+func (s *scheduler) settings(svc models.Service) (time.Duration, time.Duration, int) {
+	interval, timeout := svc.Interval.D(), svc.Timeout.D()
+	if interval <= 0 {
+		interval = s.interval
+	}
+	if timeout <= 0 {
+		timeout = s.timeout
+	}
+	return interval, timeout, svc.Priority
+}
+
+// jittered spreads d by ±jitter
+func (s *scheduler) jittered(d time.Duration) time.Duration {
+	if s.jitter <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*s.jitter*float64(d))
+}
+
+// add schedules a service. Its first check is spread over one interval
+// when spread is set, and due immediately otherwise.
+func (s *scheduler) add(svc models.Service, spread bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[svc.ID]; ok {
+		return
+	}
+	interval, timeout, priority := s.settings(svc)
+	if e, ok := s.running[svc.ID]; ok {
+		// Removed and added back while its check runs: revive the entry rather
+		// than start a second check of the same service; it is rescheduled
+		// when the running check finishes
+		e.gone = false
+		e.Interval, e.Timeout, e.Priority = models.Duration(interval), models.Duration(timeout), priority
+		s.entries[svc.ID] = e
+		return
+	}
+	e := &schedEntry{index: -1}
+	e.ID, e.Interval, e.Timeout, e.Priority = svc.ID, models.Duration(interval), models.Duration(timeout), priority
+	e.NextDue = time.Now()
+	if spread {
+		e.NextDue = e.NextDue.Add(time.Duration(rand.Int63n(int64(interval))))
+	}
+	s.entries[svc.ID] = e
+	heap.Push(&s.waiting, e)
+	s.signal()
+}
+
+// update re-reads the settings of a service and makes it due now
+func (s *scheduler) update(svc models.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[svc.ID]
+	if !ok {
+		return
+	}
+	interval, timeout, priority := s.settings(svc)
+	e.Interval, e.Timeout, e.Priority = models.Duration(interval), models.Duration(timeout), priority
+	s.triggerLocked(e, time.Now())
+	s.signal()
+}
+
+// remove forgets a service
+func (s *scheduler) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[id]; ok {
+		s.removeLocked(e)
+	}
+}
+
+func (s *scheduler) removeLocked(e *schedEntry) {
+	e.gone = true
+	delete(s.entries, e.ID)
+	if e.index < 0 {
+		return
+	}
+	if e.ready {
+		heap.Remove(&s.ready, e.index)
+	} else {
+		heap.Remove(&s.waiting, e.index)
+	}
+}
+
+// triggerAll makes every waiting service due now
+func (s *scheduler) triggerAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, e := range s.entries {
+		s.triggerLocked(e, now)
+	}
+	s.signal()
+}
+
+func (s *scheduler) triggerLocked(e *schedEntry, now time.Time) {
+	if e.ready || e.index < 0 {
+		return // Already due or running; running entries are rescheduled when they finish
+	}
+	e.NextDue = now
+	heap.Fix(&s.waiting, e.index)
+}
+
+func (s *scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// start launches the workers and runs the dispatcher; it does not return
+func (s *scheduler) start() {
+	for i := 0; i < s.workers; i++ {
+		go s.worker()
+	}
+
+	window := time.NewTicker(s.interval)
+	defer window.Stop()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		for s.waiting.Len() > 0 && !s.waiting[0].NextDue.After(now) {
+			e := heap.Pop(&s.waiting).(*schedEntry)
+			e.ready = true
+			heap.Push(&s.ready, e)
+			s.cond.Signal()
+		}
+		next := time.Hour
+		if s.waiting.Len() > 0 {
+			next = s.waiting[0].NextDue.Sub(now)
+		}
+		s.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-window.C:
+			s.closeWindow()
+		}
+	}
+}
+
+// worker takes due checks by priority and reschedules them when done
+func (s *scheduler) worker() {
+	for {
+		s.mu.Lock()
+		for s.ready.Len() == 0 {
+			s.cond.Wait()
+		}
+		e := heap.Pop(&s.ready).(*schedEntry)
+		e.ready = false
+		e.Running = true
+		s.running[e.ID] = e
+		start := time.Now()
+		lag := start.Sub(e.NextDue)
+		e.LastStart, e.LastLag = start, models.Duration(lag)
+		if lag > s.windowLag {
+			s.windowLag = lag
+		}
+		s.busy++
+		timeout := e.Timeout.D()
+		s.mu.Unlock()
+
+		s.run(e.ID, timeout)
+
+		s.mu.Lock()
+		end := time.Now()
+		elapsed := end.Sub(start)
+		s.busy--
+		s.busyTime += end.Sub(laterOf(start, s.windowStart))
+		s.checks++
+		e.Running = false
+		delete(s.running, e.ID)
+		e.LastDuration = models.Duration(elapsed)
+
+		// Keep the cadence, but never queue a check that is already late:
+		// a missed slot is counted as an overrun instead of stacking up.
+		interval := e.Interval.D()
+		next := e.NextDue.Add(s.jittered(interval))
+		if lag > interval || elapsed > interval || next.Before(end) {
+			s.overruns++
+			e.Overruns++
+			s.lastOverrun = &Overrun{ServiceID: e.ID, Lag: models.Duration(lag), Duration: models.Duration(elapsed), Interval: e.Interval, At: end}
+			log.Printf("Check overrun for %s: started %s late, took %s (interval %s)",
+				e.ID, lag.Round(time.Millisecond), elapsed.Round(time.Millisecond), interval)
+			next = end.Add(s.jittered(interval) / 10)
+		}
+		e.NextDue = next
+		if !e.gone {
+			heap.Push(&s.waiting, e)
+			s.signal()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// closeWindow computes utilisation and lag over the window that just ended
+func (s *scheduler) closeWindow() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, e := range s.entries {
+		if e.Running {
+			s.busyTime += now.Sub(laterOf(e.LastStart, s.windowStart))
+		}
+	}
+	if span := now.Sub(s.windowStart); span > 0 && s.workers > 0 {
+		s.utilisation = float64(s.busyTime) / (float64(s.workers) * float64(span))
+	}
+	s.maxLag = s.windowLag
+	s.busyTime, s.windowLag, s.windowStart = 0, 0, now
+}
+
+func (s *scheduler) stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SchedulerStats{
+		Workers:     s.workers,
+		BusyWorkers: s.busy,
+		Scheduled:   len(s.entries),
+		Queued:      s.ready.Len(),
+		Checks:      s.checks,
+		Overruns:    s.overruns,
+		LastOverrun: s.lastOverrun,
+		MaxLag:      models.Duration(s.maxLag),
+		Utilisation: s.utilisation,
+		Window:      models.Duration(s.interval),
+	}
+}
+
+func (s *scheduler) schedules() []ServiceSchedule {
+	s.mu.Lock()
+	list := make([]ServiceSchedule, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, e.ServiceSchedule)
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].NextDue.Before(list[j].NextDue) })
+	return list
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestSchedulerPassesTimeout(t *testing.T) {
+	got := make(chan time.Duration, 4)
+	s := newScheduler(func(id string, timeout time.Duration) {
+		if id == "own" || id == "default" {
+			got <- timeout
+		}
+	})
+	s.workers = 1
+	s.interval = time.Hour
+	s.add(models.Service{ID: "own", Timeout: models.Duration(3 * time.Second), Priority: 1}, false)
+	s.add(models.Service{ID: "default"}, false)
+	go s.start() // Never returns; the workers idle once both checks ran
+
+	for _, want := range []time.Duration{3 * time.Second, DefaultTimeout} {
+		select {
+		case timeout := <-got:
+			if timeout != want {
+				t.Errorf("got timeout %s, want %s", timeout, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("check did not run")
+		}
+	}
+}
+
+func TestSchedulerPriorityOrder(t *testing.T) {
+	order := make(chan string, 4)
+	s := newScheduler(func(id string, timeout time.Duration) { order <- id })
+	s.workers = 1
+	s.interval = time.Hour
+	s.add(models.Service{ID: "low"}, false)
+	s.add(models.Service{ID: "high", Priority: 10}, false)
+	s.add(models.Service{ID: "mid", Priority: 5}, false)
+	s.add(models.Service{ID: "low2"}, false) // Due after low
+	go s.start()
+
+	for _, want := range []string{"high", "mid", "low", "low2"} {
+		select {
+		case id := <-order:
+			if id != want {
+				t.Fatalf("got %s, want %s", id, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s did not run", want)
+		}
+	}
+}
+
+func TestSchedulerOverrun(t *testing.T) {
+	done := make(chan struct{}, 1)
+	s := newScheduler(func(id string, timeout time.Duration) {
+		time.Sleep(30 * time.Millisecond)
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+	})
+	s.workers = 1
+	s.jitter = 0
+	s.interval = time.Hour
+	s.add(models.Service{ID: "slow", Interval: models.Duration(10 * time.Millisecond)}, false)
+	s.add(models.Service{ID: "fast"}, false)
+	go s.start()
+	<-done
+	<-done
+
+	// A finished check is rescheduled right after the worker frees up
+	deadline := time.Now().Add(2 * time.Second)
+	for s.stats().Checks < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	st := s.stats()
+	if st.Overruns == 0 || st.LastOverrun == nil || st.LastOverrun.ServiceID != "slow" {
+		t.Fatalf("got %+v, want an overrun of slow", st)
+	}
+	for _, sc := range s.schedules() {
+		if sc.ID == "fast" && sc.Overruns != 0 {
+			t.Errorf("fast got %d overruns", sc.Overruns)
+		}
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	s := newScheduler(nil)
+	s.jitter = 0.1
+	for i := 0; i < 1000; i++ {
+		if d := s.jittered(time.Second); d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("got %s, want within 10%% of 1s", d)
+		}
+	}
+	s.jitter = 0
+	if d := s.jittered(time.Second); d != time.Second {
+		t.Errorf("got %s without jitter", d)
+	}
+}
+
+func TestSchedulerUtilisation(t *testing.T) {
+	s := newScheduler(nil)
+	s.workers = 2
+	now := time.Now()
+	s.windowStart = now.Add(-time.Second)
+	s.busyTime = 500 * time.Millisecond // Finished checks
+	running := &schedEntry{index: -1}
+	running.ID, running.Running, running.LastStart = "busy", true, now.Add(-500*time.Millisecond)
+	s.entries["busy"] = running
+	s.windowLag = 3 * time.Second
+
+	s.closeWindow()
+	st := s.stats()
+	// One second of busy time over two workers and a one-second window
+	if st.Utilisation < 0.45 || st.Utilisation > 0.5 {
+		t.Errorf("got utilisation %.3f, want about 0.5", st.Utilisation)
+	}
+	if st.MaxLag.D() != 3*time.Second {
+		t.Errorf("got max lag %s", st.MaxLag.D())
+	}
+	if s.busyTime != 0 || s.windowLag != 0 {
+		t.Errorf("window not reset")
+	}
+}
+
+func TestSchedulerReAddWhileRunning(t *testing.T) {
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	running, maxRunning, runs := 0, 0, 0
+	s := newScheduler(func(id string, timeout time.Duration) {
+		mu.Lock()
+		running++
+		runs++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		started <- struct{}{}
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	s.workers = 2
+	s.interval = time.Hour
+	svc := models.Service{ID: "svc"}
+	s.add(svc, false)
+	go s.start()
+	<-started
+
+	// Removed and added back while the check runs
+	s.remove(svc.ID)
+	s.add(models.Service{ID: "svc", Priority: 3}, false)
+	select {
+	case <-started:
+		t.Fatal("a second check started while the first one runs")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.stats().Checks < 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if maxRunning != 1 || runs != 1 {
+		t.Errorf("got %d runs, %d at once", runs, maxRunning)
+	}
+	sc := s.schedules()
+	if len(sc) != 1 || sc[0].Priority != 3 || sc[0].Running || sc[0].NextDue.Before(time.Now()) {
+		t.Errorf("got %+v, want svc rescheduled with the new settings", sc)
+	}
+}
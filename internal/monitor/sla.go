@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// DefaultSLO is the availability target used for services without an explicit SLO
+const DefaultSLO = 99.0
+
+// slaCacheTTL bounds how often the full history is re-read for SLA reports
+const slaCacheTTL = time.Minute
+
+// SLAWindows are the rolling windows reported by SLAReport
+var SLAWindows = []struct {
+	Name     string
+	Duration time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// WindowStats is the availability of a service or category over one window.
+// Uptime is weighted by time: each check covers the time until the next one,
+// at most one check interval, so gaps in sampling count neither as up nor as
+// down. UptimePercent and ErrorBudgetRemaining are nil when no checks were recorded.
+type WindowStats struct {
+	Samples              int             `json:"samples"`
+	Healthy              int             `json:"healthy"`
+	Observed             models.Duration `json:"observed"` // Time covered by counted checks
+	Downtime             models.Duration `json:"downtime"` // Part of Observed covered by failing checks
+	UptimePercent        *float64        `json:"uptime_percent"`
+	ErrorBudgetRemaining *float64        `json:"error_budget_remaining,omitempty"` // Percent of budget left, negative when overspent
+}
+
+// ServiceSLA holds rolling uptime for one service
+type ServiceSLA struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Category string                 `json:"category"`
+	SLO      float64                `json:"slo"`
+	Windows  map[string]WindowStats `json:"windows"`
+}
+
+// CategorySLA rolls up the services of one category
+type CategorySLA struct {
+	Category      string                 `json:"category"`
+	Services      int                    `json:"services"`
+	MeetingSLO24h int                    `json:"meeting_slo_24h"`
+	Windows       map[string]WindowStats `json:"windows"`
+}
+
+// SLAReport is the full SLA view computed from recorded checks
+type SLAReport struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	DefaultSLO  float64       `json:"default_slo"`
+	Services    []ServiceSLA  `json:"services"`
+	Categories  []CategorySLA `json:"categories"`
+}
+
+// SetDefaultSLO overrides the availability target for services without one
+func (m *Monitor) SetDefaultSLO(slo float64) {
+	m.slaMu.Lock()
+	m.defaultSLO = slo
+	m.slaCache = nil
+	m.slaMu.Unlock()
+}
+
+// SLAReport computes rolling uptime and error budgets from the history store.
+// Results are cached briefly since each report reads 30 days of history.
+func (m *Monitor) SLAReport() (*SLAReport, error) {
+	m.slaMu.Lock()
+	defer m.slaMu.Unlock()
+
+	now := time.Now()
+	if m.slaCache != nil && now.Sub(m.slaCache.GeneratedAt) < slaCacheTTL {
+		return m.slaCache, nil
+	}
+
+	defaultSLO := m.defaultSLO
+	if defaultSLO <= 0 {
+		defaultSLO = DefaultSLO
+	}
+
+	report := &SLAReport{
+		GeneratedAt: now,
+		DefaultSLO:  defaultSLO,
+		Services:    []ServiceSLA{},
+		Categories:  []CategorySLA{},
+	}
+	categories := make(map[string]*CategorySLA)
+
+	longest := SLAWindows[len(SLAWindows)-1].Duration
+	for _, svc := range m.registry.GetAll() {
+		entry := ServiceSLA{
+			ID:       svc.ID,
+			Name:     svc.Name,
+			Category: svc.Category,
+			SLO:      svc.SLO,
+			Windows:  make(map[string]WindowStats),
+		}
+		if entry.SLO <= 0 {
+			entry.SLO = defaultSLO
+		}
+
+		interval, _, _ := m.sched.settings(svc)
+		var records []history.Record
+		if m.history != nil {
+			var err error
+			records, err = m.history.Query(entry.ID, now.Add(-longest), now, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cat, ok := categories[entry.Category]
+		if !ok {
+			cat = &CategorySLA{Category: entry.Category, Windows: make(map[string]WindowStats)}
+			categories[entry.Category] = cat
+		}
+		cat.Services++
+
+		for _, w := range SLAWindows {
+			stats := windowStats(records, now.Add(-w.Duration), interval, entry.SLO)
+			entry.Windows[w.Name] = stats
+
+			agg := cat.Windows[w.Name]
+			agg.Samples += stats.Samples
+			agg.Healthy += stats.Healthy
+			agg.Observed += stats.Observed
+			agg.Downtime += stats.Downtime
+			cat.Windows[w.Name] = agg
+
+			if w.Name == "24h" && stats.ErrorBudgetRemaining != nil && *stats.ErrorBudgetRemaining >= 0 {
+				cat.MeetingSLO24h++
+			}
+		}
+		report.Services = append(report.Services, entry)
+	}
+
+	for _, cat := range categories {
+		for name, agg := range cat.Windows {
+			agg.UptimePercent = uptimePercent(agg.Observed.D(), agg.Downtime.D())
+			cat.Windows[name] = agg
+		}
+		report.Categories = append(report.Categories, *cat)
+	}
+
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].ID < report.Services[j].ID })
+	sort.Slice(report.Categories, func(i, j int) bool { return report.Categories[i].Category < report.Categories[j].Category })
+
+	m.slaCache = report
+	return report, nil
+}
+
+// windowStats weighs the checks recorded since the window start by the time
+// until the next check, capped at interval per represented check
+func windowStats(records []history.Record, since time.Time, interval time.Duration, slo float64) WindowStats {
+	var stats WindowStats
+	var observed, down float64
+	for i, rec := range records {
+		if rec.Timestamp.Before(since) {
+			continue
+		}
+		counted, healthy := rec.UptimeSamples(), rec.HealthyCount()
+		stats.Samples += counted
+		stats.Healthy += healthy
+		if counted == 0 {
+			continue
+		}
+
+		n := rec.SampleCount()
+		span := interval * time.Duration(n) // The latest check stands for its whole slot
+		if i+1 < len(records) {
+			if gap := records[i+1].Timestamp.Sub(rec.Timestamp); gap < span {
+				span = gap
+			}
+		}
+		observed += float64(span) * float64(counted) / float64(n)
+		down += float64(span) * float64(counted-healthy) / float64(n)
+	}
+	stats.Observed = models.Duration(observed)
+	stats.Downtime = models.Duration(down)
+	stats.UptimePercent = uptimePercent(stats.Observed.D(), stats.Downtime.D())
+	if stats.UptimePercent != nil {
+		stats.ErrorBudgetRemaining = errorBudgetRemaining(*stats.UptimePercent, slo)
+	}
+	return stats
+}
+
+func uptimePercent(observed, down time.Duration) *float64 {
+	if observed <= 0 {
+		return nil
+	}
+	pct := float64(observed-down) / float64(observed) * 100
+	return &pct
+}
+
+// errorBudgetRemaining returns the share of the allowed downtime still unused
+func errorBudgetRemaining(uptime, slo float64) *float64 {
+	budget := 100 - slo
+	spent := 100 - uptime
+	var remaining float64
+	if budget <= 0 {
+		if spent > 0 {
+			remaining = -100
+		} else {
+			remaining = 100
+		}
+	} else {
+		remaining = (1 - spent/budget) * 100
+	}
+	return &remaining
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func TestWindowStatsIsTimeWeighted(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := time.Minute
+	var records []history.Record
+	// An hour up, checked every minute
+	for i := 0; i < 60; i++ {
+		records = append(records, history.Record{Timestamp: start.Add(time.Duration(i) * interval), Status: models.StatusHealthy})
+	}
+	// A two-hour gap (dashboard down), then an hour down, checked every minute
+	downStart := start.Add(3 * time.Hour)
+	for i := 0; i < 60; i++ {
+		records = append(records, history.Record{Timestamp: downStart.Add(time.Duration(i) * interval), Status: models.StatusUnhealthy})
+	}
+
+	stats := windowStats(records, start, interval, 99)
+	if stats.Samples != 120 || stats.Healthy != 60 {
+		t.Fatalf("got %d/%d samples, want 60/120", stats.Healthy, stats.Samples)
+	}
+	if stats.Observed.D() != 2*time.Hour || stats.Downtime.D() != time.Hour {
+		t.Fatalf("got observed %s, downtime %s; want 2h, 1h", stats.Observed.D(), stats.Downtime.D())
+	}
+	if *stats.UptimePercent != 50 {
+		t.Errorf("got uptime %.2f, want 50", *stats.UptimePercent)
+	}
+}
+
+func TestWindowStatsSparseSamples(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := time.Minute
+	// One failing sample every 10 minutes for an hour, then 60 healthy ones:
+	// each failing sample only covers its own slot
+	var records []history.Record
+	for i := 0; i < 6; i++ {
+		records = append(records, history.Record{Timestamp: start.Add(time.Duration(i) * 10 * time.Minute), Status: models.StatusUnhealthy})
+	}
+	upStart := start.Add(time.Hour)
+	for i := 0; i < 60; i++ {
+		records = append(records, history.Record{Timestamp: upStart.Add(time.Duration(i) * interval), Status: models.StatusHealthy})
+	}
+
+	stats := windowStats(records, start, interval, 99)
+	if stats.Downtime.D() != 6*time.Minute || stats.Observed.D() != 66*time.Minute {
+		t.Errorf("got observed %s, downtime %s; want 66m, 6m", stats.Observed.D(), stats.Downtime.D())
+	}
+}
+
+func TestWindowStatsDownsampled(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Timestamp: start, Status: models.StatusHealthy, Samples: 10, Healthy: 8},
+		{Timestamp: start.Add(5 * time.Minute), Status: models.StatusMaintenance, Samples: 10, Healthy: 0, Excluded: 10},
+	}
+	stats := windowStats(records, start, 30*time.Second, 99)
+	if stats.Observed.D() != 5*time.Minute || stats.Downtime.D() != time.Minute {
+		t.Errorf("got observed %s, downtime %s; want 5m, 1m", stats.Observed.D(), stats.Downtime.D())
+	}
+	if stats.UptimePercent == nil || *stats.UptimePercent != 80 {
+		t.Errorf("got uptime %v, want 80", stats.UptimePercent)
+	}
+}
@@ -0,0 +1,238 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/docker"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Membership states stored in Service.NetworkStatus
+const (
+	StatusAttached    = "attached"
+	StatusMissing     = "missing"
+	StatusNoContainer = "no_container"
+)
+
+// ErrRemediationDisabled is returned by Connect when the admin flag is off
+var ErrRemediationDisabled = errors.New("network remediation is disabled (set NETWORK_REMEDIATION=true)")
+
+// Membership is the audit result for one service
+type Membership struct {
+	ServiceID string   `json:"id"`
+	Container string   `json:"container,omitempty"`
+	State     string   `json:"container_state,omitempty"`
+	Status    string   `json:"status"`
+	Networks  []string `json:"networks,omitempty"`
+}
+
+// Report is the result of an audit run
+type Report struct {
+	Network   string       `json:"network"`
+	CheckedAt time.Time    `json:"checked_at"`
+	Attached  int          `json:"attached"`
+	Missing   []Membership `json:"missing"`
+	NotFound  []Membership `json:"not_found"`
+	Services  []Membership `json:"services"`
+}
+
+// Auditor checks that monitored containers are attached to the expected Docker network
+type Auditor struct {
+	client        *docker.Client
+	registry      *models.Registry
+	network       string
+	remediation   bool
+	autoRemediate bool
+	auditLog      *audit.Log
+
+	mu   sync.Mutex
+	last *Report
+}
+
+// NewAuditor creates an auditor for network (e.g. "pentest_network").
+// remediation enables Connect; autoRemediate additionally connects missing
+// running containers after every audit.
+func NewAuditor(client *docker.Client, registry *models.Registry, network string, remediation, autoRemediate bool, auditLog *audit.Log) *Auditor {
+	return &Auditor{
+		client:        client,
+		registry:      registry,
+		network:       network,
+		remediation:   remediation,
+		autoRemediate: remediation && autoRemediate,
+		auditLog:      auditLog,
+	}
+}
+
+// RemediationEnabled reports whether Connect is allowed
+func (a *Auditor) RemediationEnabled() bool {
+	return a.remediation
+}
+
+// Audit compares running containers with the registry and stores each
+// service's membership in Service.NetworkStatus
+func (a *Auditor) Audit(ctx context.Context) (*Report, error) {
+	containers, err := a.client.ListContainers(ctx, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]docker.Container, len(containers))
+	for _, c := range containers {
+		byName[c.Name()] = c
+	}
+
+	report := &Report{
+		Network:   a.network,
+		CheckedAt: time.Now(),
+		Missing:   []Membership{},
+		NotFound:  []Membership{},
+		Services:  []Membership{},
+	}
+
+	for _, svc := range a.registry.GetAll() {
+		m := Membership{ServiceID: svc.ID, Status: StatusNoContainer}
+		for _, name := range containerNames(&svc) {
+			c, ok := byName[name]
+			if !ok {
+				continue
+			}
+			m.Container = name
+			m.State = c.State
+			m.Networks = c.NetworkNames()
+			sort.Strings(m.Networks)
+			m.Status = StatusMissing
+			if _, attached := c.NetworkSettings.Networks[a.network]; attached {
+				m.Status = StatusAttached
+			}
+			break
+		}
+		a.setStatus(svc.ID, m.Status)
+
+		switch m.Status {
+		case StatusAttached:
+			report.Attached++
+		case StatusMissing:
+			report.Missing = append(report.Missing, m)
+		default:
+			report.NotFound = append(report.NotFound, m)
+		}
+		report.Services = append(report.Services, m)
+	}
+
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].ServiceID < report.Services[j].ServiceID })
+	sort.Slice(report.Missing, func(i, j int) bool { return report.Missing[i].ServiceID < report.Missing[j].ServiceID })
+	sort.Slice(report.NotFound, func(i, j int) bool { return report.NotFound[i].ServiceID < report.NotFound[j].ServiceID })
+
+	a.mu.Lock()
+	a.last = report
+	a.mu.Unlock()
+
+	if a.autoRemediate {
+		for _, m := range report.Missing {
+			if m.State != "running" {
+				continue
+			}
+			if err := a.Connect(ctx, m.ServiceID, "auto-remediation", ""); err != nil {
+				log.Printf("Auto-remediation of %s failed: %v", m.ServiceID, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+// Last returns the most recent report (nil before the first audit)
+func (a *Auditor) Last() *Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+// Connect attaches the service's container to the expected network and
+// writes an audit log entry for actor, with the name the caller claimed if any.
+// It requires remediation to be enabled.
+func (a *Auditor) Connect(ctx context.Context, serviceID, actor, claimedActor string) error {
+	if !a.remediation {
+		return ErrRemediationDisabled
+	}
+
+	var container string
+	a.mu.Lock()
+	if a.last != nil {
+		for _, m := range a.last.Services {
+			if m.ServiceID == serviceID {
+				container = m.Container
+			}
+		}
+	}
+	a.mu.Unlock()
+	if container == "" {
+		return fmt.Errorf("no container known for %s (run an audit first)", serviceID)
+	}
+
+	err := a.client.ConnectNetwork(ctx, a.network, container)
+	entry := audit.Entry{
+		Actor:        actor,
+		ClaimedActor: claimedActor,
+		Action:       "network_connect",
+		Target:       serviceID,
+		Detail:       fmt.Sprintf("docker network connect %s %s", a.network, container),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if a.auditLog != nil {
+		if logErr := a.auditLog.Record(entry); logErr != nil {
+			log.Printf("Failed to write audit log: %v", logErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Connected %s (%s) to %s on behalf of %s", serviceID, container, a.network, actor)
+	a.setStatus(serviceID, StatusAttached)
+	return nil
+}
+
+// setStatus records the network status of a service
+func (a *Auditor) setStatus(id, status string) {
+	a.registry.Update(id, func(s *models.Service) bool {
+		changed := s.NetworkStatus != status
+		s.NetworkStatus = status
+		return changed
+	})
+}
+
+// Run audits periodically. It is meant to run in its own goroutine.
+func (a *Auditor) Run(interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := a.Audit(ctx); err != nil {
+			log.Printf("Network audit failed: %v", err)
+		}
+		cancel()
+		time.Sleep(interval)
+	}
+}
+
+// containerNames lists the container names a service may run under,
+// mirroring checker.GetInternalHosts
+func containerNames(svc *models.Service) []string {
+	names := []string{}
+	if svc.ContainerName != "" {
+		names = append(names, svc.ContainerName)
+	}
+	if svc.DockerName != "" {
+		names = append(names, svc.DockerName)
+	}
+	if svc.ID != "" {
+		names = append(names, svc.ID+"-app-1", svc.ID)
+	}
+	return names
+}
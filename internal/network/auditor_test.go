@@ -0,0 +1,223 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/audit"
+	"github.com/baditaflorin/go_services_dashboard/internal/docker"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// fakeDocker serves GET /containers/json and POST /networks/{name}/connect on a unix socket
+type fakeDocker struct {
+	mu         sync.Mutex
+	containers []docker.Container
+	connected  []string // "network container" of every connect call
+	connectErr string   // message of a 500 answer to connect, if set
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/containers/json":
+		json.NewEncoder(w).Encode(f.containers)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/networks/") && strings.HasSuffix(r.URL.Path, "/connect"):
+		if f.connectErr != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": f.connectErr})
+			return
+		}
+		var body struct{ Container string }
+		json.NewDecoder(r.Body).Decode(&body)
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/networks/"), "/connect")
+		f.connected = append(f.connected, name+" "+body.Container)
+	default:
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}
+}
+
+func newFakeDocker(t *testing.T) (*fakeDocker, *docker.Client) {
+	t.Helper()
+	// Unix socket paths are short; t.TempDir() can exceed the limit
+	dir, err := os.MkdirTemp("", "dock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeDocker{}
+	srv := &http.Server{Handler: fake}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+	return fake, docker.NewClient("unix://" + socket)
+}
+
+func container(name, state string, networks ...string) docker.Container {
+	c := docker.Container{ID: name + "-id", Names: []string{"/" + name}, State: state}
+	c.NetworkSettings.Networks = map[string]docker.EndpointSettings{}
+	for _, n := range networks {
+		c.NetworkSettings.Networks[n] = docker.EndpointSettings{}
+	}
+	return c
+}
+
+// setup registers attached, missing and gone services and their containers
+func setup(t *testing.T, remediation bool) (*fakeDocker, *models.Registry, *audit.Log, *Auditor) {
+	t.Helper()
+	fake, client := newFakeDocker(t)
+	fake.containers = []docker.Container{
+		container("attached-app-1", "running", "pentest_network", "bridge"),
+		container("custom_name", "running", "bridge"),
+		container("stopped", "exited"),
+	}
+	registry := models.NewRegistry()
+	registry.Add(models.Service{ID: "attached"})
+	registry.Add(models.Service{ID: "missing", ContainerName: "custom_name"})
+	registry.Add(models.Service{ID: "stopped"})
+	registry.Add(models.Service{ID: "gone"})
+
+	log, err := audit.NewLog(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, registry, log, NewAuditor(client, registry, "pentest_network", remediation, false, log)
+}
+
+func TestAudit(t *testing.T) {
+	_, registry, _, a := setup(t, false)
+	if a.Last() != nil {
+		t.Fatal("expected no report before the first audit")
+	}
+	report, err := a.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Attached != 1 || len(report.Missing) != 2 || len(report.NotFound) != 1 || len(report.Services) != 4 {
+		t.Fatalf("got %+v", report)
+	}
+	if m := report.Missing[0]; m.ServiceID != "missing" || m.Container != "custom_name" || m.State != "running" ||
+		len(m.Networks) != 1 || m.Networks[0] != "bridge" {
+		t.Errorf("got %+v", m)
+	}
+	if m := report.Missing[1]; m.ServiceID != "stopped" || m.State != "exited" {
+		t.Errorf("got %+v", m)
+	}
+	if m := report.NotFound[0]; m.ServiceID != "gone" || m.Container != "" {
+		t.Errorf("got %+v", m)
+	}
+	if a.Last() != report {
+		t.Error("expected the report to be kept")
+	}
+
+	want := map[string]string{"attached": StatusAttached, "missing": StatusMissing, "stopped": StatusMissing, "gone": StatusNoContainer}
+	for id, status := range want {
+		if svc, _ := registry.Get(id); svc.NetworkStatus != status {
+			t.Errorf("%s: got status %q, want %q", id, svc.NetworkStatus, status)
+		}
+	}
+}
+
+func TestAutoRemediation(t *testing.T) {
+	fake, client := newFakeDocker(t)
+	fake.containers = []docker.Container{
+		container("missing", "running", "bridge"),
+		container("stopped", "exited"),
+	}
+	registry := models.NewRegistry()
+	registry.Add(models.Service{ID: "missing"})
+	registry.Add(models.Service{ID: "stopped"})
+	log, err := audit.NewLog(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAuditor(client, registry, "pentest_network", true, true, log)
+	if _, err := a.Audit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.connected) != 1 || fake.connected[0] != "pentest_network missing" {
+		t.Errorf("got connects %v, want only the running container", fake.connected)
+	}
+	entries, _ := log.Recent(10)
+	if len(entries) != 1 || entries[0].Actor != "auto-remediation" {
+		t.Errorf("got entries %+v", entries)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	fake, registry, log, a := setup(t, true)
+	ctx := context.Background()
+
+	if err := a.Connect(ctx, "missing", "203.0.113.9", "alice"); err == nil {
+		t.Error("expected an error before the first audit")
+	}
+	if _, err := a.Audit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Connect(ctx, "missing", "203.0.113.9", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.connected) != 1 || fake.connected[0] != "pentest_network custom_name" {
+		t.Errorf("got connects %v", fake.connected)
+	}
+	if svc, _ := registry.Get("missing"); svc.NetworkStatus != StatusAttached {
+		t.Errorf("got status %q", svc.NetworkStatus)
+	}
+
+	fake.mu.Lock()
+	fake.connectErr = "endpoint already exists"
+	fake.mu.Unlock()
+	err := a.Connect(ctx, "stopped", "203.0.113.9", "")
+	if err == nil || !strings.Contains(err.Error(), "endpoint already exists") {
+		t.Fatalf("got %v", err)
+	}
+	if svc, _ := registry.Get("stopped"); svc.NetworkStatus != StatusMissing {
+		t.Errorf("got status %q after a failed connect", svc.NetworkStatus)
+	}
+
+	entries, err := log.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if e := entries[0]; e.Actor != "203.0.113.9" || e.ClaimedActor != "alice" || e.Action != "network_connect" ||
+		e.Target != "missing" || e.Detail != "docker network connect pentest_network custom_name" || e.Error != "" || e.Time.IsZero() {
+		t.Errorf("got %+v", e)
+	}
+	if e := entries[1]; e.Target != "stopped" || e.ClaimedActor != "" || !strings.Contains(e.Error, "endpoint already exists") {
+		t.Errorf("got %+v", e)
+	}
+}
+
+func TestConnectDisabled(t *testing.T) {
+	fake, _, log, a := setup(t, false)
+	if _, err := a.Audit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Connect(context.Background(), "missing", "203.0.113.9", ""); !errors.Is(err, ErrRemediationDisabled) {
+		t.Fatalf("got %v", err)
+	}
+	if len(fake.connected) != 0 {
+		t.Errorf("got connects %v", fake.connected)
+	}
+	if entries, _ := log.Recent(10); len(entries) != 0 {
+		t.Errorf("got entries %+v", entries)
+	}
+}
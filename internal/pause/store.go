@@ -0,0 +1,150 @@
+package pause
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// ErrNotPaused is returned when resuming something that is not paused
+var ErrNotPaused = errors.New("not paused")
+
+// Store keeps service and category pauses in a JSON file. Expired pauses
+// stop matching right away and are dropped on the next write.
+type Store struct {
+	path string
+
+	mu     sync.RWMutex
+	pauses map[string]models.Pause // Keyed by scope:target
+}
+
+func key(scope, target string) string {
+	return scope + ":" + target
+}
+
+// NewStore loads (or creates) the store at path
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create pause dir: %w", err)
+	}
+	s := &Store{path: path, pauses: make(map[string]models.Pause)}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		var list []models.Pause
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		for _, p := range list {
+			s.pauses[key(p.Scope, p.Target)] = p
+		}
+	}
+	return s, nil
+}
+
+// List returns the pauses in effect at now, oldest first
+func (s *Store) List(now time.Time) []models.Pause {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]models.Pause, 0, len(s.pauses))
+	for _, p := range s.pauses {
+		if !p.Expired(now) {
+			list = append(list, p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].PausedAt.Before(list[j].PausedAt)
+	})
+	return list
+}
+
+// Pause stores p, replacing an earlier pause of the same target
+func (s *Store) Pause(p models.Pause, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	k := key(p.Scope, p.Target)
+	prev, had := s.pauses[k]
+	s.pauses[k] = p
+	if err := s.save(); err != nil {
+		if had {
+			s.pauses[k] = prev
+		} else {
+			delete(s.pauses, k)
+		}
+		return err
+	}
+	return nil
+}
+
+// Resume removes the pause of a target and returns it
+func (s *Store) Resume(scope, target string, now time.Time) (models.Pause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key(scope, target)
+	p, ok := s.pauses[k]
+	if !ok || p.Expired(now) {
+		return models.Pause{}, ErrNotPaused
+	}
+	delete(s.pauses, k)
+	if err := s.save(); err != nil {
+		s.pauses[k] = p
+		return models.Pause{}, err
+	}
+	return p, nil
+}
+
+// Match returns the pause in effect for svc, its own before its category's,
+// or nil. It is safe to call on a nil store.
+func (s *Store) Match(svc *models.Service, now time.Time) *models.Pause {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range []string{key(models.PauseService, svc.ID), key(models.PauseCategory, svc.Category)} {
+		if p, ok := s.pauses[k]; ok && !p.Expired(now) {
+			return &p
+		}
+	}
+	return nil
+}
+
+// prune drops expired pauses
+func (s *Store) prune(now time.Time) {
+	for k, p := range s.pauses {
+		if p.Expired(now) {
+			delete(s.pauses, k)
+		}
+	}
+}
+
+// save writes the pauses atomically
+func (s *Store) save() error {
+	list := make([]models.Pause, 0, len(s.pauses))
+	for _, p := range s.pauses {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].PausedAt.Before(list[j].PausedAt)
+	})
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,136 @@
+package pause
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func newStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pauses.json")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestPersistence(t *testing.T) {
+	s, path := newStore(t)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+	if err := s.Pause(models.Pause{Scope: models.PauseService, Target: "svc", Reason: "migration", PausedBy: "alice", PausedAt: now, Until: &until}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Pause(models.Pause{Scope: models.PauseCategory, Target: "tools", PausedAt: now.Add(time.Minute)}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restart reads the same pauses back
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reopened.List(now)
+	if len(list) != 2 {
+		t.Fatalf("got %d pauses, want 2", len(list))
+	}
+	if p := list[0]; p.Target != "svc" || p.Reason != "migration" || p.PausedBy != "alice" || p.Until == nil || !p.Until.Equal(until) {
+		t.Errorf("got %+v", p)
+	}
+	if p := list[1]; p.Scope != models.PauseCategory || p.Target != "tools" || p.Until != nil {
+		t.Errorf("got %+v", p)
+	}
+
+	if _, err := reopened.Resume(models.PauseCategory, "tools", now); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := reopened.List(now); len(list) != 1 || list[0].Target != "svc" {
+		t.Errorf("got %+v after resume and restart", list)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	s, path := newStore(t)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+	if err := s.Pause(models.Pause{Scope: models.PauseService, Target: "svc", PausedAt: now, Until: &until}, now); err != nil {
+		t.Fatal(err)
+	}
+	svc := &models.Service{ID: "svc"}
+
+	if s.Match(svc, until.Add(-time.Second)) == nil {
+		t.Error("expected a match before Until")
+	}
+	if p := s.Match(svc, until); p != nil {
+		t.Errorf("got %+v at Until, want nil", p)
+	}
+	if list := s.List(until); len(list) != 0 {
+		t.Errorf("got %+v at Until, want none", list)
+	}
+	if _, err := s.Resume(models.PauseService, "svc", until); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("got %v resuming an expired pause, want ErrNotPaused", err)
+	}
+	if _, err := s.Resume(models.PauseService, "other", now); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("got %v resuming an unknown pause, want ErrNotPaused", err)
+	}
+
+	// The next write drops the expired pause from the file
+	if err := s.Pause(models.Pause{Scope: models.PauseService, Target: "other", PausedAt: until}, until); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := reopened.List(now); len(list) != 1 || list[0].Target != "other" {
+		t.Errorf("got %+v, want only the new pause", list)
+	}
+}
+
+func TestServicePauseBeforeCategory(t *testing.T) {
+	s, _ := newStore(t)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc := &models.Service{ID: "svc", Category: "tools"}
+	other := &models.Service{ID: "other", Category: "tools"}
+
+	if p := s.Match(svc, now); p != nil {
+		t.Fatalf("got %+v, want nil", p)
+	}
+	if err := s.Pause(models.Pause{Scope: models.PauseCategory, Target: "tools", Reason: "category", PausedAt: now}, now); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.Match(svc, now); p == nil || p.Scope != models.PauseCategory {
+		t.Errorf("got %+v, want the category pause", p)
+	}
+	if err := s.Pause(models.Pause{Scope: models.PauseService, Target: "svc", Reason: "service", PausedAt: now}, now); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.Match(svc, now); p == nil || p.Reason != "service" {
+		t.Errorf("got %+v, want the service pause", p)
+	}
+	if p := s.Match(other, now); p == nil || p.Reason != "category" {
+		t.Errorf("got %+v for a sibling, want the category pause", p)
+	}
+
+	// Resuming the service falls back to its category
+	if _, err := s.Resume(models.PauseService, "svc", now); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.Match(svc, now); p == nil || p.Reason != "category" {
+		t.Errorf("got %+v, want the category pause", p)
+	}
+
+	var nilStore *Store
+	if p := nilStore.Match(svc, now); p != nil {
+		t.Errorf("got %+v from a nil store", p)
+	}
+}
@@ -0,0 +1,149 @@
+package statuspage
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxFeedItems caps the incidents listed in a feed
+const maxFeedItems = 50
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Link      atomLink    `xml:"link"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// RSS renders the incidents of s as an RSS 2.0 feed
+func (p *Page) RSS(s *Summary) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         s.Title + " incidents",
+			Link:          p.link(""),
+			Description:   "Incidents reported on " + s.Title,
+			LastBuildDate: s.UpdatedAt.UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, n := range feedNotices(s) {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       n.Title + " (" + n.Status + ")",
+			Link:        p.link(n.ID),
+			GUID:        rssGUID{Value: "incident-" + n.ID, IsPermaLink: false},
+			PubDate:     n.StartedAt.UTC().Format(time.RFC1123Z),
+			Description: describe(n),
+		})
+	}
+	return marshalXML(feed)
+}
+
+// Atom renders the incidents of s as an Atom feed
+func (p *Page) Atom(s *Summary) ([]byte, error) {
+	feed := atomFeed{
+		ID:      p.link(""),
+		Title:   s.Title + " incidents",
+		Updated: s.UpdatedAt.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: p.link("")},
+	}
+	for _, n := range feedNotices(s) {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        p.link(n.ID),
+			Title:     n.Title + " (" + n.Status + ")",
+			Updated:   n.Updates[0].At.UTC().Format(time.RFC3339),
+			Published: n.StartedAt.UTC().Format(time.RFC3339),
+			Link:      atomLink{Href: p.link(n.ID), Rel: "alternate"},
+			Content:   atomContent{Type: "text", Value: describe(n)},
+		})
+	}
+	return marshalXML(feed)
+}
+
+// link returns the URL of the page, or of one incident on it
+func (p *Page) link(incidentID string) string {
+	link := strings.TrimRight(p.cfg.URL, "/") + "/status"
+	if incidentID != "" {
+		link += "#incident-" + incidentID
+	}
+	return link
+}
+
+// feedNotices returns the newest incidents first, ongoing or not
+func feedNotices(s *Summary) []Notice {
+	list := append([]Notice(nil), s.Incidents...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].StartedAt.After(list[j].StartedAt)
+	})
+	if len(list) > maxFeedItems {
+		list = list[:maxFeedItems]
+	}
+	return list
+}
+
+// describe lists the updates of a notice, newest first
+func describe(n Notice) string {
+	var b strings.Builder
+	b.WriteString("Affected: " + strings.Join(n.Components, ", ") + "\n")
+	for _, u := range n.Updates {
+		b.WriteString(u.At.UTC().Format("2006-01-02 15:04 MST") + " - " + u.Status + ": " + u.Message + "\n")
+	}
+	return b.String()
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
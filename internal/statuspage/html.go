@@ -0,0 +1,101 @@
+package statuspage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Render writes the HTML page of s. It is self-contained so it can be served
+// on its own listener without the dashboard's assets.
+func (p *Page) Render(w io.Writer, s *Summary) error {
+	return pageTemplate.Execute(w, s)
+}
+
+var pageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"label": func(status string) string {
+		label := strings.ReplaceAll(status, "_", " ")
+		if label == "" {
+			return ""
+		}
+		return strings.ToUpper(label[:1]) + label[1:]
+	},
+	"duration": func(d models.Duration) string {
+		return d.D().Round(time.Minute).String()
+	},
+	"percent": func(v *float64) string {
+		if v == nil {
+			return "no data"
+		}
+		return fmt.Sprintf("%.2f%%", *v)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Title}}</title>
+<link rel="alternate" type="application/rss+xml" title="{{.Title}} incidents" href="/status/incidents.rss">
+<link rel="alternate" type="application/atom+xml" title="{{.Title}} incidents" href="/status/incidents.atom">
+<style>
+body { margin: 0; font-family: Inter, system-ui, sans-serif; background: #0f0f1a; color: #fff; }
+main { max-width: 880px; margin: 0 auto; padding: 32px 16px; }
+h1 { margin: 0 0 4px; }
+.muted { color: #a0a0b0; }
+.banner { margin: 24px 0; padding: 16px 20px; border-radius: 8px; font-weight: 600; }
+.card { background: #1a1a2e; border: 1px solid rgba(255,255,255,0.1); border-radius: 8px; padding: 16px 20px; margin-bottom: 12px; }
+.row { display: flex; justify-content: space-between; align-items: baseline; gap: 12px; }
+.bars { display: flex; gap: 2px; margin: 12px 0 4px; height: 32px; }
+.bars span { flex: 1; border-radius: 2px; }
+ul { list-style: none; padding: 0; margin: 8px 0 0; }
+li { padding: 4px 0; }
+.operational { background: #10b981; } .degraded_performance { background: #f59e0b; }
+.partial_outage { background: #f97316; } .major_outage { background: #ef4444; }
+.maintenance { background: #3b82f6; } .no_data { background: #252540; }
+.text-operational { color: #10b981; } .text-degraded_performance { color: #f59e0b; }
+.text-partial_outage { color: #f97316; } .text-major_outage { color: #ef4444; } .text-maintenance { color: #3b82f6; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{with .Description}}<p class="muted">{{.}}</p>{{end}}
+<div class="banner {{.Status}}">{{if eq .Status "operational"}}All systems operational{{else}}{{label .Status}}{{end}}</div>
+
+{{range .Incidents}}{{if ne .Status "resolved"}}
+<div class="card" id="incident-{{.ID}}">
+<div class="row"><strong>{{.Title}}</strong><span class="text-major_outage">{{label .Status}}</span></div>
+<ul>{{range .Updates}}<li><span class="muted">{{.At.UTC.Format "Jan 2, 15:04 MST"}}</span> {{.Message}}</li>{{end}}</ul>
+</div>
+{{end}}{{end}}
+
+{{range .Groups}}
+<div class="card">
+<div class="row"><strong>{{.Name}}</strong><span class="text-{{.Status}}">{{label .Status}}</span></div>
+{{with .Description}}<div class="muted">{{.}}</div>{{end}}
+<div class="bars">{{range .Bars}}<span class="{{.Status}}" title="{{.Date}}: {{percent .UptimePercent}}"></span>{{end}}</div>
+<div class="row muted"><span>{{len .Bars}} days ago</span><span>{{percent .UptimePercent}} uptime</span><span>Today</span></div>
+{{if .Components}}<ul>{{range .Components}}<li class="row"><span>{{.Name}}</span><span class="text-{{.Status}}">{{label .Status}}</span></li>{{end}}</ul>{{end}}
+</div>
+{{end}}
+
+<h2>Past incidents</h2>
+{{range .Incidents}}{{if eq .Status "resolved"}}
+<div class="card" id="incident-{{.ID}}">
+<div class="row"><strong>{{.Title}}</strong><span class="muted">{{.StartedAt.UTC.Format "Jan 2, 15:04 MST"}} &middot; {{duration .Duration}}</span></div>
+<ul>{{range .Updates}}<li><span class="muted">{{.At.UTC.Format "Jan 2, 15:04 MST"}}</span> {{.Message}}</li>{{end}}</ul>
+</div>
+{{end}}{{else}}<p class="muted">No incidents in the last {{.Days}} days.</p>{{end}}
+
+<p class="muted">Updated {{.UpdatedAt.UTC.Format "Jan 2, 15:04 MST"}} &middot;
+<a class="muted" href="/status/incidents.rss">RSS</a> &middot;
+<a class="muted" href="/status/incidents.atom">Atom</a> &middot;
+<a class="muted" href="/status/summary.json">JSON</a></p>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,481 @@
+package statuspage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/history"
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Public statuses of components, groups and the whole page, best first
+const (
+	StatusOperational   = "operational"
+	StatusDegraded      = "degraded_performance"
+	StatusMaintenance   = "maintenance"
+	StatusPartialOutage = "partial_outage"
+	StatusMajorOutage   = "major_outage"
+	StatusNoData        = "no_data" // Uptime bars without recorded checks
+)
+
+// Public incident states
+const (
+	NoticeInvestigating = "investigating"
+	NoticeIdentified    = "identified" // Acknowledged by an operator
+	NoticeResolved      = "resolved"
+)
+
+const (
+	// defaultDays is the number of daily uptime bars without "days"
+	defaultDays = 30
+	// maxDays bounds "days"; older history is gone anyway
+	maxDays = 90
+	// cacheTTL bounds how often history is re-read for the page
+	cacheTTL = time.Minute
+)
+
+// Config is config/statuspage.json
+type Config struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	URL         string          `json:"url,omitempty"`                   // Public base URL, used for feed links
+	Days        int             `json:"days,omitempty"`                  // Daily uptime bars, default 30
+	MinIncident models.Duration `json:"min_incident_duration,omitempty"` // Shorter resolved incidents are not shown
+	Groups      []Group         `json:"groups,omitempty"`                // Default: one group per category
+}
+
+// Group is a public component group. It covers every service listed in
+// Services, in one of Categories or carrying one of Tags.
+type Group struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Categories     []string `json:"categories,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Services       []string `json:"services,omitempty"`
+	ShowComponents bool     `json:"show_components,omitempty"` // List the services by display name
+}
+
+// covers reports whether the group selects svc
+func (g *Group) covers(svc *models.Service) bool {
+	for _, id := range g.Services {
+		if id == svc.ID {
+			return true
+		}
+	}
+	for _, c := range g.Categories {
+		if c == svc.Category {
+			return true
+		}
+	}
+	for _, t := range g.Tags {
+		for _, st := range svc.Tags {
+			if t == st {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Summary is the public, redacted view of the services: no IDs, ports,
+// container names, URLs or errors
+type Summary struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Status      string        `json:"status"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Groups      []GroupStatus `json:"groups"`
+	Incidents   []Notice      `json:"incidents"` // Ongoing first, then newest
+	Days        int           `json:"days"`
+	Uptime      *float64      `json:"uptime_percent"` // Over Days, all groups
+}
+
+// GroupStatus is one component group on the page
+type GroupStatus struct {
+	Name          string      `json:"name"`
+	Description   string      `json:"description,omitempty"`
+	Status        string      `json:"status"`
+	UptimePercent *float64    `json:"uptime_percent"` // Over Days, nil without recorded checks
+	Bars          []Bar       `json:"bars"`           // One per day, oldest first
+	Components    []Component `json:"components,omitempty"`
+}
+
+// Component is one service of a group that lists its components
+type Component struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// Bar is the uptime of one day
+type Bar struct {
+	Date          string   `json:"date"` // YYYY-MM-DD, UTC
+	UptimePercent *float64 `json:"uptime_percent"`
+	Status        string   `json:"status"`
+}
+
+// Notice is an incident as shown to the public
+type Notice struct {
+	ID         string          `json:"id"`
+	Title      string          `json:"title"`
+	Impact     string          `json:"impact"` // minor or major
+	Status     string          `json:"status"` // One of the Notice* values
+	Components []string        `json:"components"`
+	StartedAt  time.Time       `json:"started_at"`
+	ResolvedAt *time.Time      `json:"resolved_at,omitempty"`
+	Duration   models.Duration `json:"duration"`
+	Updates    []Update        `json:"updates"` // Newest first
+}
+
+// Update is one step of a public incident
+type Update struct {
+	At      time.Time `json:"at"`
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+}
+
+// Page builds the public status page from the registry, the check history
+// and the incidents. History and incidents may be nil.
+type Page struct {
+	cfg       Config
+	registry  *models.Registry
+	history   *history.Store
+	incidents *incident.Tracker
+
+	mu    sync.Mutex
+	cache *Summary
+}
+
+// New creates a status page
+func New(cfg Config, r *models.Registry, store *history.Store, incidents *incident.Tracker) *Page {
+	if cfg.Title == "" {
+		cfg.Title = "Service Status"
+	}
+	if cfg.Days <= 0 {
+		cfg.Days = defaultDays
+	}
+	if cfg.Days > maxDays {
+		cfg.Days = maxDays
+	}
+	return &Page{cfg: cfg, registry: r, history: store, incidents: incidents}
+}
+
+// Config returns the page settings
+func (p *Page) Config() Config {
+	return p.cfg
+}
+
+// Summary returns the current page. It is cached briefly since it reads
+// Days of history for every service.
+func (p *Page) Summary() (*Summary, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.cache != nil && now.Sub(p.cache.UpdatedAt) < cacheTTL {
+		return p.cache, nil
+	}
+
+	services := p.registry.GetAll()
+	groups := p.groups(services)
+	from := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-p.cfg.Days)
+
+	summary := &Summary{
+		Title:       p.cfg.Title,
+		Description: p.cfg.Description,
+		Status:      StatusOperational,
+		UpdatedAt:   now,
+		Groups:      make([]GroupStatus, 0, len(groups)),
+		Days:        p.cfg.Days,
+	}
+	memberOf := make(map[string][]string) // Service ID -> group names
+	totalSamples, totalHealthy := 0, 0
+	for _, g := range groups {
+		var members []*models.Service
+		for i := range services {
+			if g.covers(&services[i]) {
+				members = append(members, &services[i])
+				memberOf[services[i].ID] = append(memberOf[services[i].ID], g.Name)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		gs, samples, healthy, err := p.groupStatus(g, members, from, now)
+		if err != nil {
+			return nil, err
+		}
+		totalSamples += samples
+		totalHealthy += healthy
+		if worse(gs.Status, summary.Status) {
+			summary.Status = gs.Status
+		}
+		summary.Groups = append(summary.Groups, gs)
+	}
+	summary.Uptime = uptimePercent(totalSamples, totalHealthy)
+	summary.Incidents = p.notices(services, groups, memberOf, from, now)
+
+	p.cache = summary
+	return summary, nil
+}
+
+// groups returns the configured groups, or one per category
+func (p *Page) groups(services []models.Service) []Group {
+	if len(p.cfg.Groups) > 0 {
+		return p.cfg.Groups
+	}
+	seen := make(map[string]bool)
+	var groups []Group
+	for _, svc := range services {
+		if svc.Category == "" || seen[svc.Category] {
+			continue
+		}
+		seen[svc.Category] = true
+		g := Group{Name: svc.Category, Categories: []string{svc.Category}}
+		if meta, ok := p.registry.CategoryMeta(svc.Category); ok {
+			if meta.DisplayName != "" {
+				g.Name = meta.DisplayName
+			}
+			g.Description = meta.Description
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// groupStatus computes the current status and daily uptime of one group
+func (p *Page) groupStatus(g Group, members []*models.Service, from, now time.Time) (GroupStatus, int, int, error) {
+	gs := GroupStatus{Name: g.Name, Description: g.Description}
+
+	statuses := make([]string, 0, len(members))
+	for _, svc := range members {
+		st := publicStatus(svc.Status)
+		if st != "" {
+			statuses = append(statuses, st)
+		}
+		if g.ShowComponents {
+			if st == "" {
+				st = StatusOperational
+			}
+			gs.Components = append(gs.Components, Component{Name: displayName(svc), Status: st})
+		}
+	}
+	gs.Status = rollup(statuses)
+	sort.Slice(gs.Components, func(i, j int) bool { return gs.Components[i].Name < gs.Components[j].Name })
+
+	samples := make([]int, p.cfg.Days)
+	healthy := make([]int, p.cfg.Days)
+	if p.history != nil {
+		for _, svc := range members {
+			records, err := p.history.Query(svc.ID, from, now, 24*time.Hour)
+			if err != nil {
+				return gs, 0, 0, err
+			}
+			for _, rec := range records {
+				day := int(rec.Timestamp.Sub(from) / (24 * time.Hour))
+				if day < 0 || day >= p.cfg.Days {
+					continue
+				}
+				samples[day] += rec.UptimeSamples()
+				healthy[day] += rec.HealthyCount()
+			}
+		}
+	}
+
+	totalSamples, totalHealthy := 0, 0
+	gs.Bars = make([]Bar, p.cfg.Days)
+	for day := range gs.Bars {
+		uptime := uptimePercent(samples[day], healthy[day])
+		gs.Bars[day] = Bar{
+			Date:          from.AddDate(0, 0, day).Format("2006-01-02"),
+			UptimePercent: uptime,
+			Status:        barStatus(uptime),
+		}
+		totalSamples += samples[day]
+		totalHealthy += healthy[day]
+	}
+	gs.UptimePercent = uptimePercent(totalSamples, totalHealthy)
+	return gs, totalSamples, totalHealthy, nil
+}
+
+// notices converts the incidents of the period touching a group into public notices
+func (p *Page) notices(services []models.Service, groups []Group, memberOf map[string][]string, from, now time.Time) []Notice {
+	notices := []Notice{}
+	if p.incidents == nil {
+		return notices
+	}
+	byID := make(map[string]*models.Service, len(services))
+	for i := range services {
+		byID[services[i].ID] = &services[i]
+	}
+	showComponents := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		showComponents[g.Name] = g.ShowComponents
+	}
+
+	for _, inc := range p.incidents.List(incident.Filter{Since: from}, now) {
+		if !inc.Open() && inc.Duration.D() < p.cfg.MinIncident.D() {
+			continue
+		}
+		var components []string
+		subject := ""
+		switch inc.Scope {
+		case incident.ScopeService:
+			components = memberOf[inc.Target]
+			if svc, ok := byID[inc.Target]; ok && len(components) == 1 && showComponents[components[0]] {
+				subject = displayName(svc)
+			}
+		case incident.ScopeCategory:
+			seen := make(map[string]bool)
+			for _, id := range inc.Services {
+				for _, name := range memberOf[id] {
+					if !seen[name] {
+						seen[name] = true
+						components = append(components, name)
+					}
+				}
+			}
+			sort.Strings(components)
+		}
+		if len(components) == 0 {
+			continue // Not part of the public page
+		}
+		notices = append(notices, newNotice(inc, components, subject))
+	}
+	sort.SliceStable(notices, func(i, j int) bool {
+		oi, oj := notices[i].Status != NoticeResolved, notices[j].Status != NoticeResolved
+		if oi != oj {
+			return oi
+		}
+		return notices[i].StartedAt.After(notices[j].StartedAt)
+	})
+	return notices
+}
+
+// newNotice redacts an incident: only its impact, the affected components
+// and the open/acknowledged/resolved steps are published
+func newNotice(inc incident.Incident, components []string, subject string) Notice {
+	if subject == "" {
+		subject = components[0]
+		for _, c := range components[1:] {
+			subject += ", " + c
+		}
+	}
+	title := "Degraded performance of " + subject
+	if inc.Severity == incident.SeverityMajor {
+		title = "Outage of " + subject
+	}
+
+	n := Notice{
+		ID:         inc.ID,
+		Title:      title,
+		Impact:     inc.Severity,
+		Status:     NoticeInvestigating,
+		Components: components,
+		StartedAt:  inc.StartedAt,
+		ResolvedAt: inc.ResolvedAt,
+		Duration:   inc.Duration,
+		Updates: []Update{{
+			At:      inc.StartedAt,
+			Status:  NoticeInvestigating,
+			Message: "We are investigating issues affecting " + subject + ".",
+		}},
+	}
+	if inc.Acknowledged != nil {
+		n.Status = NoticeIdentified
+		n.Updates = append(n.Updates, Update{
+			At:      inc.Acknowledged.At,
+			Status:  NoticeIdentified,
+			Message: "The issue has been identified and is being worked on.",
+		})
+	}
+	if inc.ResolvedAt != nil {
+		n.Status = NoticeResolved
+		n.Updates = append(n.Updates, Update{
+			At:      *inc.ResolvedAt,
+			Status:  NoticeResolved,
+			Message: "This incident has been resolved.",
+		})
+	}
+	for i, j := 0, len(n.Updates)-1; i < j; i, j = i+1, j-1 {
+		n.Updates[i], n.Updates[j] = n.Updates[j], n.Updates[i]
+	}
+	return n
+}
+
+// publicStatus maps a service status to a component status, or "" for
+// statuses that say nothing about availability (paused, not checked yet)
+func publicStatus(status string) string {
+	switch status {
+	case models.StatusHealthy:
+		return StatusOperational
+	case models.StatusDegraded:
+		return StatusDegraded
+	case models.StatusUnhealthy, models.StatusCircuitOpen:
+		return StatusMajorOutage
+	case models.StatusMaintenance:
+		return StatusMaintenance
+	}
+	return ""
+}
+
+// rank orders the public statuses from best to worst
+var rank = map[string]int{
+	StatusOperational:   0,
+	StatusMaintenance:   1,
+	StatusDegraded:      2,
+	StatusPartialOutage: 3,
+	StatusMajorOutage:   4,
+}
+
+func worse(a, b string) bool {
+	return rank[a] > rank[b]
+}
+
+// rollup combines component statuses: a group is in a major outage only when
+// every component is down, otherwise in a partial one
+func rollup(statuses []string) string {
+	down, worst := 0, StatusOperational
+	for _, st := range statuses {
+		if st == StatusMajorOutage {
+			down++
+		}
+		if worse(st, worst) {
+			worst = st
+		}
+	}
+	if down > 0 && down < len(statuses) {
+		return StatusPartialOutage
+	}
+	return worst
+}
+
+// barStatus grades the uptime of one day
+func barStatus(uptime *float64) string {
+	switch {
+	case uptime == nil:
+		return StatusNoData
+	case *uptime >= 99.5:
+		return StatusOperational
+	case *uptime >= 95:
+		return StatusPartialOutage
+	}
+	return StatusMajorOutage
+}
+
+func uptimePercent(samples, healthy int) *float64 {
+	if samples == 0 {
+		return nil
+	}
+	pct := float64(healthy) / float64(samples) * 100
+	return &pct
+}
+
+func displayName(svc *models.Service) string {
+	if svc.DisplayName != "" {
+		return svc.DisplayName
+	}
+	return svc.Name
+}
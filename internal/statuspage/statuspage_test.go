@@ -0,0 +1,155 @@
+package statuspage
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/incident"
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+func newTestTracker(t *testing.T) *incident.Tracker {
+	t.Helper()
+	tr, err := incident.NewTracker(filepath.Join(t.TempDir(), "incidents.json"), incident.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+// outage records an incident of svc from start, resolved after d unless d is 0
+func outage(tr *incident.Tracker, svc models.Service, start time.Time, d time.Duration) {
+	svc.Status, svc.LastChecked = models.StatusUnhealthy, start
+	tr.Observe(svc, start)
+	if d > 0 {
+		svc.Status, svc.LastChecked = models.StatusHealthy, start.Add(d)
+		tr.Observe(svc, start.Add(d))
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	secret := models.Service{
+		ID:            "svc-internal-id",
+		Name:          "Search",
+		Category:      "tools",
+		Port:          8137,
+		DockerName:    "go_search_docker",
+		ContainerName: "go-search-container",
+		HealthURL:     "https://search-private.example.net/health",
+		ExampleURL:    "https://search-private.example.net/example?q=1",
+		Status:        models.StatusUnhealthy,
+		LastError:     "dial tcp 10.20.30.40:8137: connection refused",
+		RootCause:     models.RootCauseContainerDown,
+		Diagnosis:     &models.Diagnosis{RootCause: models.RootCauseContainerDown, Summary: "refused on go_search_docker:8137"},
+	}
+	registry := models.NewRegistry()
+	registry.Add(secret)
+	registry.Add(models.Service{ID: "other-id", Name: "Other", Category: "tools", Port: 8138, Status: models.StatusHealthy})
+
+	tr := newTestTracker(t)
+	outage(tr, secret, time.Now().Add(-2*time.Hour), time.Hour)
+	outage(tr, secret, time.Now().Add(-time.Minute), 0)
+	open := tr.List(incident.Filter{State: incident.StateOpen}, time.Now())[0]
+	if _, err := tr.Acknowledge(open.ID, "alice", "ssh into 10.20.30.40", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.AddNote(open.ID, "alice", "restarted go-search-container", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	page := New(Config{
+		Title: "Status",
+		URL:   "https://status.example.com",
+		Groups: []Group{
+			{Name: "Tools", Categories: []string{"tools"}, ShowComponents: true},
+		},
+	}, registry, nil, tr)
+	summary, err := page.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Incidents) != 2 || summary.Incidents[0].Status != NoticeIdentified || summary.Groups[0].Status != StatusPartialOutage {
+		t.Fatalf("got %+v", summary)
+	}
+
+	rendered := map[string][]byte{}
+	rendered["json"], err = json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered["rss"], err = page.RSS(summary); err != nil {
+		t.Fatal(err)
+	}
+	if rendered["atom"], err = page.Atom(summary); err != nil {
+		t.Fatal(err)
+	}
+	var html bytes.Buffer
+	if err := page.Render(&html, summary); err != nil {
+		t.Fatal(err)
+	}
+	rendered["html"] = html.Bytes()
+
+	for format, data := range rendered {
+		if !bytes.Contains(data, []byte("Search")) {
+			t.Errorf("%s: does not name the component", format)
+		}
+		for _, leak := range []string{
+			"svc-internal-id", "other-id", "8137", "8138", "go_search_docker", "go-search-container",
+			"search-private", "10.20.30.40", "connection refused", "container_down", "alice", "restarted",
+		} {
+			if bytes.Contains(data, []byte(leak)) {
+				t.Errorf("%s: leaks %q", format, leak)
+			}
+		}
+	}
+}
+
+func TestMinIncident(t *testing.T) {
+	svc := models.Service{ID: "svc", Name: "Search", Category: "tools", Status: models.StatusHealthy}
+	registry := models.NewRegistry()
+	registry.Add(svc)
+	tr := newTestTracker(t)
+	now := time.Now()
+	outage(tr, svc, now.Add(-5*time.Hour), 2*time.Minute) // Short, hidden
+	outage(tr, svc, now.Add(-4*time.Hour), time.Hour)     // Long, shown
+	outage(tr, svc, now.Add(-time.Minute), 0)             // Ongoing, shown however short
+
+	page := New(Config{MinIncident: models.Duration(10 * time.Minute)}, registry, nil, tr)
+	summary, err := page.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, n := range summary.Incidents {
+		got = append(got, n.Status+" "+n.Duration.D().Round(time.Minute).String())
+	}
+	want := "investigating 1m0s,resolved 1h0m0s"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
+
+func TestRollup(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		want     string
+	}{
+		{nil, StatusOperational},
+		{[]string{StatusOperational, StatusOperational}, StatusOperational},
+		{[]string{StatusOperational, StatusMaintenance}, StatusMaintenance},
+		{[]string{StatusMaintenance, StatusDegraded}, StatusDegraded},
+		{[]string{StatusOperational, StatusMajorOutage}, StatusPartialOutage},
+		{[]string{StatusDegraded, StatusMajorOutage, StatusMajorOutage}, StatusPartialOutage},
+		{[]string{StatusMajorOutage}, StatusMajorOutage},
+		{[]string{StatusMajorOutage, StatusMajorOutage}, StatusMajorOutage},
+	}
+	for _, tt := range tests {
+		if got := rollup(tt.statuses); got != tt.want {
+			t.Errorf("rollup(%v) = %s, want %s", tt.statuses, got, tt.want)
+		}
+	}
+}
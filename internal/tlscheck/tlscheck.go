@@ -0,0 +1,238 @@
+package tlscheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// Options configures certificate inspection
+type Options struct {
+	WarnDays     int            // Warn when a certificate expires within this many days
+	CriticalDays int            // Escalate when it expires within this many days
+	Timeout      time.Duration  // Per-host handshake timeout
+	RootCAs      *x509.CertPool // Trusted roots, nil uses the system pool
+	Concurrency  int            // Hosts inspected in parallel
+}
+
+// DefaultOptions returns the thresholds used when nothing is configured
+func DefaultOptions() Options {
+	return Options{
+		WarnDays:     21,
+		CriticalDays: 7,
+		Timeout:      10 * time.Second,
+		Concurrency:  8,
+	}
+}
+
+// Checker inspects the certificates of every service's public hosts
+type Checker struct {
+	registry *models.Registry
+	opts     Options
+	onChange func(svc models.Service, prev *models.CertStatus, cur models.CertStatus)
+}
+
+// NewChecker creates a certificate checker. onChange, when non-nil, is called
+// for every host whose status differs from the previous run.
+func NewChecker(registry *models.Registry, opts Options, onChange func(svc models.Service, prev *models.CertStatus, cur models.CertStatus)) *Checker {
+	def := DefaultOptions()
+	if opts.Timeout <= 0 {
+		opts.Timeout = def.Timeout
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = def.Concurrency
+	}
+	return &Checker{registry: registry, opts: opts, onChange: onChange}
+}
+
+// Inspect performs a TLS handshake with host:port and evaluates the presented chain
+func (c *Checker) Inspect(ctx context.Context, host string, port int) models.CertStatus {
+	now := time.Now()
+	status := models.CertStatus{Host: host, CheckedAt: now}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: c.opts.Timeout},
+		// Verification is done below so that broken chains can still be described
+		Config: &tls.Config{ServerName: host, InsecureSkipVerify: true},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		status.Status = models.CertError
+		status.Error = err.Error()
+		return status
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		status.Status = models.CertError
+		status.Error = "no certificate presented"
+		return status
+	}
+
+	leaf := certs[0]
+	status.Subject = leaf.Subject.CommonName
+	status.Issuer = leaf.Issuer.CommonName
+	if len(leaf.Issuer.Organization) > 0 {
+		status.Issuer = leaf.Issuer.Organization[0] + " " + leaf.Issuer.CommonName
+	}
+	status.SANs = leaf.DNSNames
+	status.NotBefore = leaf.NotBefore
+	status.NotAfter = leaf.NotAfter
+	status.DaysRemaining = int(leaf.NotAfter.Sub(now).Hours() / 24)
+
+	if err := leaf.VerifyHostname(host); err != nil {
+		status.HostnameMismatch = true
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: c.opts.RootCAs, Intermediates: intermediates, CurrentTime: now}); err != nil {
+		status.ChainError = err.Error()
+	}
+
+	switch {
+	case now.After(leaf.NotAfter):
+		status.Status = models.CertExpired
+	case status.HostnameMismatch || status.ChainError != "":
+		status.Status = models.CertInvalid
+	case status.DaysRemaining < c.opts.CriticalDays:
+		status.Status = models.CertCritical
+	case status.DaysRemaining < c.opts.WarnDays:
+		status.Status = models.CertWarning
+	default:
+		status.Status = models.CertOK
+	}
+	return status
+}
+
+// CheckAll inspects every service and stores the results in Service.TLS
+func (c *Checker) CheckAll(ctx context.Context) {
+	type job struct {
+		id    string
+		hosts []hostPort
+	}
+	var jobs []job
+	for _, svc := range c.registry.GetAll() {
+		if hosts := publicHosts(&svc); len(hosts) > 0 {
+			jobs = append(jobs, job{id: svc.ID, hosts: hosts})
+		}
+	}
+
+	sem := make(chan struct{}, c.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results := make([]models.CertStatus, 0, len(j.hosts))
+			for _, hp := range j.hosts {
+				results = append(results, c.Inspect(ctx, hp.host, hp.port))
+			}
+			c.store(j.id, results)
+		}(j)
+	}
+	wg.Wait()
+}
+
+// store saves results on the service and reports changed hosts
+func (c *Checker) store(id string, results []models.CertStatus) {
+	var prev map[string]models.CertStatus
+	snapshot, ok := c.registry.Update(id, func(s *models.Service) bool {
+		prev = make(map[string]models.CertStatus, len(s.TLS))
+		for _, p := range s.TLS {
+			prev[p.Host] = p
+		}
+		if sameCerts(s.TLS, results) {
+			return false // Publishing every run would only move CheckedAt
+		}
+		s.TLS = results
+		return true
+	})
+	if !ok {
+		return // Removed while inspecting
+	}
+
+	for _, cur := range results {
+		p, seen := prev[cur.Host]
+		if seen && p.Status == cur.Status {
+			continue
+		}
+		if cur.Status != models.CertOK {
+			log.Printf("TLS %s (%s): %s %s%s", cur.Host, id, cur.Status, cur.ChainError, cur.Error)
+		}
+		if c.onChange != nil {
+			var prevPtr *models.CertStatus
+			if seen {
+				prevPtr = &p
+			}
+			c.onChange(snapshot, prevPtr, cur)
+		}
+	}
+}
+
+// sameCerts reports whether a and b differ in nothing but when they were checked
+func sameCerts(a, b []models.CertStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.CheckedAt, y.CheckedAt = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run checks periodically. It is meant to run in its own goroutine.
+func (c *Checker) Run(interval time.Duration) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		c.CheckAll(ctx)
+		cancel()
+		time.Sleep(interval)
+	}
+}
+
+type hostPort struct {
+	host string
+	port int
+}
+
+// publicHosts returns the distinct https hosts of HealthURL and ExampleURL
+func publicHosts(svc *models.Service) []hostPort {
+	seen := make(map[hostPort]bool)
+	var out []hostPort
+	for _, raw := range []string{svc.HealthURL, svc.ExampleURL} {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+			continue
+		}
+		hp := hostPort{host: u.Hostname(), port: 443}
+		if p, err := strconv.Atoi(u.Port()); err == nil {
+			hp.port = p
+		}
+		if !seen[hp] {
+			seen[hp] = true
+			out = append(out, hp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].host < out[j].host })
+	return out
+}
@@ -0,0 +1,205 @@
+package tlscheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/baditaflorin/go_services_dashboard/internal/models"
+)
+
+// testCA issues certificates for local TLS servers
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root", Organization: []string{"Dashboard"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue creates a leaf for the given names (IP addresses or DNS names) valid until notAfter
+func (ca *testCA) issue(t *testing.T, notAfter time.Time, names ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: names[0]},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, n := range names {
+		if ip := net.ParseIP(n); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, n)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// serve starts a local TLS server presenting cert and returns its host and port
+func serve(t *testing.T, cert tls.Certificate) (string, int) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return u.Hostname(), port
+}
+
+func TestInspect(t *testing.T) {
+	ca := newTestCA(t)
+	day := 24 * time.Hour
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		cert     tls.Certificate
+		roots    *x509.CertPool
+		want     string
+		mismatch bool
+		chainErr bool
+	}{
+		{name: "valid", cert: ca.issue(t, now.Add(60*day), "127.0.0.1"), roots: ca.pool, want: models.CertOK},
+		{name: "warning", cert: ca.issue(t, now.Add(10*day+time.Hour), "127.0.0.1"), roots: ca.pool, want: models.CertWarning},
+		{name: "critical", cert: ca.issue(t, now.Add(3*day+time.Hour), "127.0.0.1"), roots: ca.pool, want: models.CertCritical},
+		{name: "expired", cert: ca.issue(t, now.Add(-day), "127.0.0.1"), roots: ca.pool, want: models.CertExpired, chainErr: true},
+		{name: "untrusted chain", cert: ca.issue(t, now.Add(60*day), "127.0.0.1"), roots: x509.NewCertPool(), want: models.CertInvalid, chainErr: true},
+		{name: "hostname mismatch", cert: ca.issue(t, now.Add(60*day), "other.example"), roots: ca.pool, want: models.CertInvalid, mismatch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := serve(t, tt.cert)
+			c := NewChecker(models.NewRegistry(), Options{WarnDays: 21, CriticalDays: 7, RootCAs: tt.roots}, nil)
+			got := c.Inspect(context.Background(), host, port)
+
+			if got.Status != tt.want {
+				t.Errorf("got status %s, want %s (%+v)", got.Status, tt.want, got)
+			}
+			if got.HostnameMismatch != tt.mismatch {
+				t.Errorf("got hostname mismatch %v, want %v", got.HostnameMismatch, tt.mismatch)
+			}
+			if (got.ChainError != "") != tt.chainErr {
+				t.Errorf("got chain error %q, want one: %v", got.ChainError, tt.chainErr)
+			}
+			if got.Issuer != "Dashboard Test Root" {
+				t.Errorf("got issuer %q", got.Issuer)
+			}
+		})
+	}
+}
+
+func TestInspectHandshakeError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	c := NewChecker(models.NewRegistry(), Options{Timeout: time.Second}, nil)
+	got := c.Inspect(context.Background(), "127.0.0.1", port)
+	if got.Status != models.CertError || got.Error == "" {
+		t.Errorf("got %+v, want an error status", got)
+	}
+}
+
+func TestCheckAllStoresAndReportsChanges(t *testing.T) {
+	ca := newTestCA(t)
+	host, port := serve(t, ca.issue(t, time.Now().Add(10*24*time.Hour), "127.0.0.1"))
+
+	registry := models.NewRegistry()
+	registry.Add(models.Service{
+		ID:        "svc",
+		HealthURL: "https://" + net.JoinHostPort(host, strconv.Itoa(port)) + "/health",
+	})
+	var changes []string
+	c := NewChecker(registry, Options{WarnDays: 21, CriticalDays: 7, RootCAs: ca.pool}, func(svc models.Service, prev *models.CertStatus, cur models.CertStatus) {
+		changes = append(changes, cur.Status)
+	})
+
+	c.CheckAll(context.Background())
+	svc, _ := registry.Get("svc")
+	if len(svc.TLS) != 1 || svc.TLS[0].Status != models.CertWarning {
+		t.Fatalf("got %+v", svc.TLS)
+	}
+	if len(changes) != 1 || changes[0] != models.CertWarning {
+		t.Fatalf("got changes %v", changes)
+	}
+
+	// An unchanged certificate neither publishes an event nor reports a change
+	events, cancel := registry.Subscribe()
+	defer cancel()
+	c.CheckAll(context.Background())
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected %s event", ev.Kind)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if len(changes) != 1 {
+		t.Errorf("got changes %v after an unchanged run", changes)
+	}
+}
+
+func TestPublicHosts(t *testing.T) {
+	svc := &models.Service{
+		HealthURL:  "https://b.example/health",
+		ExampleURL: "https://a.example:8443/x",
+	}
+	hosts := publicHosts(svc)
+	if len(hosts) != 2 || hosts[0] != (hostPort{"a.example", 8443}) || hosts[1] != (hostPort{"b.example", 443}) {
+		t.Errorf("got %v", hosts)
+	}
+	if hosts := publicHosts(&models.Service{HealthURL: "http://plain.example/health"}); len(hosts) != 0 {
+		t.Errorf("got %v for a plain http URL", hosts)
+	}
+}